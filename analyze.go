@@ -0,0 +1,137 @@
+package govarint
+
+import (
+	"encoding/binary"
+)
+
+// An Analyzer collects sample records and recommends the field widths to
+// encode them with.
+type Analyzer struct {
+	// Number of samples by value width, for each field.
+	widths [][65]uint64
+	// Number of samples by the total number of value bits in the record.
+	recordValueBits map[int]uint64
+	uvarintBytes    uint64
+	count           uint64
+}
+
+// A Recommendation gives the field widths best suited to the samples added
+// to an Analyzer and how large records are expected to be with them.
+type Recommendation struct {
+	Fields []uint8
+	// Number of sample records the recommendation is based on.
+	Samples uint64
+	// Average encoded size of the samples using Fields.
+	BytesPerRecord float64
+	// Average size of the samples encoded value by value with
+	// binary.PutUvarint.
+	UvarintBytesPerRecord float64
+}
+
+// Create an analyzer for records with the given number of fields.
+func NewAnalyzer(fieldCount int) *Analyzer {
+	return &Analyzer{
+		widths:          make([][65]uint64, fieldCount),
+		recordValueBits: map[int]uint64{},
+	}
+}
+
+// Add a sample record.
+func (a *Analyzer) Add(values []uint32) error {
+	if len(values) != len(a.widths) {
+		return countMismatchError(len(a.widths), len(values))
+	}
+
+	buf := [binary.MaxVarintLen32]byte{}
+	valueBits := 0
+	for i, value := range values {
+		valueWidth := 32 - countLeadingZeros(value)
+		a.widths[i][valueWidth]++
+		if valueWidth > 0 {
+			valueBits += valueWidth - 1
+		}
+
+		a.uvarintBytes += uint64(binary.PutUvarint(buf[:], uint64(value)))
+	}
+
+	a.recordValueBits[valueBits]++
+	a.count++
+
+	return nil
+}
+
+// Add a sample record of 64-bit values.
+func (a *Analyzer) Add64(values []uint64) error {
+	if len(values) != len(a.widths) {
+		return countMismatchError(len(a.widths), len(values))
+	}
+
+	buf := [binary.MaxVarintLen64]byte{}
+	valueBits := 0
+	for i, value := range values {
+		valueWidth := 64 - countLeadingZeros64(value)
+		a.widths[i][valueWidth]++
+		if valueWidth > 0 {
+			valueBits += valueWidth - 1
+		}
+
+		a.uvarintBytes += uint64(binary.PutUvarint(buf[:], value))
+	}
+
+	a.recordValueBits[valueBits]++
+	a.count++
+
+	return nil
+}
+
+// Return the number of samples of the given field by value width, indexed
+// by width from 0 to 64.
+func (a *Analyzer) Histogram(field int) []uint64 {
+	histogram := make([]uint64, len(a.widths[field]))
+	copy(histogram, a.widths[field][:])
+	return histogram
+}
+
+// Recommend field widths for the samples added so far.
+//
+// The bits spent on a value do not depend on the width of its field, only
+// the width prefix does. So the narrowest field able to describe the widest
+// value observed minimises the encoded size of the samples.
+func (a *Analyzer) Recommend() Recommendation {
+	r := Recommendation{
+		Fields:  make([]uint8, len(a.widths)),
+		Samples: a.count,
+	}
+
+	prefixBits := 0
+	for i, histogram := range a.widths {
+		maxValueWidth := 0
+		for width, count := range histogram {
+			if count > 0 {
+				maxValueWidth = width
+			}
+		}
+
+		fieldWidth := 32 - countLeadingZeros(uint32(maxValueWidth))
+		if fieldWidth == 0 {
+			fieldWidth = 1
+		}
+
+		r.Fields[i] = uint8(fieldWidth)
+		prefixBits += fieldWidth
+	}
+
+	if a.count == 0 {
+		return r
+	}
+
+	totalBytes := uint64(0)
+	for valueBits, count := range a.recordValueBits {
+		totalBytes += count * uint64((prefixBits+valueBits+7)/8)
+	}
+
+	r.BytesPerRecord = float64(totalBytes) / float64(a.count)
+	r.UvarintBytesPerRecord = float64(a.uvarintBytes) / float64(a.count)
+
+	return r
+}
@@ -0,0 +1,107 @@
+package govarint
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+type analyzeTestCase struct {
+	samples [][]uint32
+	fields  []uint8
+}
+
+var (
+	analyzeTests = []analyzeTestCase{
+		{[][]uint32{{0}}, []uint8{1}},
+		{[][]uint32{{1}}, []uint8{1}},
+		{[][]uint32{{0}, {3}}, []uint8{2}},
+		{[][]uint32{{1, 8}, {2, 4}}, []uint8{2, 3}},
+		{[][]uint32{{127, 1 << 31}}, []uint8{3, 6}},
+	}
+)
+
+func TestAnalyzerRecommend(t *testing.T) {
+	for _, tc := range analyzeTests {
+		a := NewAnalyzer(len(tc.fields))
+		for _, values := range tc.samples {
+			if err := a.Add(values); err != nil {
+				t.Fatalf("Unexpected error \"%s\" for %v", err, tc)
+			}
+		}
+
+		r := a.Recommend()
+		if string(r.Fields) != string(tc.fields) {
+			t.Errorf("Expected fields %v, got %v for %v", tc.fields, r.Fields, tc)
+		}
+	}
+}
+
+// The recommended fields must be able to encode every sample, and the
+// expected size must match the actual encoded size.
+func TestAnalyzerActivities(t *testing.T) {
+	a := NewAnalyzer(8)
+	samples := [][]uint32{}
+
+	for i := 0; i < 10000; i++ {
+		values := []uint32{
+			uint32(rand.Int31n(16)),
+			uint32(rand.Int31n(16)),
+			uint32(rand.Int31n(100000000)),
+			uint32(rand.Int31n(16)),
+			uint32(rand.Int31n(100000000)),
+			uint32(rand.Int31n(16)),
+			uint32(rand.Int31n(100000000)),
+			uint32(rand.Int31n(400000000) * 10),
+		}
+
+		a.Add(values)
+		samples = append(samples, values)
+	}
+
+	r := a.Recommend()
+	if r.Samples != uint64(len(samples)) {
+		t.Errorf("Expected %d samples, got %d", len(samples), r.Samples)
+	}
+
+	totalSize := 0
+	totalUvarintSize := uint(0)
+	for _, values := range samples {
+		data, err := Encode(r.Fields, values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v with fields %v", err, values, r.Fields)
+		}
+
+		totalSize += len(data)
+		totalUvarintSize += encodeStandardVarint(roundTripTestCase{r.Fields, values})
+	}
+
+	if expected := float64(totalSize) / float64(len(samples)); expected != r.BytesPerRecord {
+		t.Errorf("Expected %f bytes per record, got %f", expected, r.BytesPerRecord)
+	}
+
+	if expected := float64(totalUvarintSize) / float64(len(samples)); expected != r.UvarintBytesPerRecord {
+		t.Errorf("Expected %f uvarint bytes per record, got %f", expected, r.UvarintBytesPerRecord)
+	}
+}
+
+func TestAnalyzerHistogram(t *testing.T) {
+	a := NewAnalyzer(1)
+	a.Add64([]uint64{0})
+	a.Add64([]uint64{5})
+	a.Add64([]uint64{6})
+	a.Add64([]uint64{1 << 40})
+
+	histogram := a.Histogram(0)
+	if histogram[0] != 1 || histogram[3] != 2 || histogram[41] != 1 {
+		t.Errorf("Unexpected histogram %v", histogram)
+	}
+
+	if r := a.Recommend(); r.Fields[0] != 6 {
+		t.Errorf("Expected field width 6, got %d", r.Fields[0])
+	}
+
+	if err := a.Add([]uint32{1, 2}); !errors.Is(err, ErrFieldCountMismatch) {
+		t.Errorf("Expected ErrFieldCountMismatch, got %v", err)
+	}
+}
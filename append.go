@@ -0,0 +1,240 @@
+package govarint
+
+import (
+	"fmt"
+)
+
+// Encode the given values like Encode does, appending the record to dst and
+// returning the extended slice. No memory is allocated if dst has enough
+// spare capacity, so a buffer can be reused across calls.
+func AppendEncode(dst []byte, fields []uint8, values []uint32) ([]byte, error) {
+	if len(fields) != len(values) {
+		return dst, countMismatchError(len(fields), len(values))
+	}
+
+	if err := validateFields(fields); err != nil {
+		return dst, err
+	}
+
+	return appendEncode(dst, fields, values)
+}
+
+// Encode the given 64-bit values like Encode64 does, see AppendEncode.
+func AppendEncode64(dst []byte, fields []uint8, values []uint64) ([]byte, error) {
+	if len(fields) != len(values) {
+		return dst, countMismatchError(len(fields), len(values))
+	}
+
+	if err := validateFields(fields); err != nil {
+		return dst, err
+	}
+
+	return appendEncode64(dst, fields, values)
+}
+
+// Decode a record like Decode does, storing the values in dst rather than
+// allocating a new slice. Returns the number of values stored, which is the
+// number of fields. A record does not describe its own field widths, so
+// they are passed in like for Decode, or see Schema.DecodeInto.
+func DecodeInto(dst []uint32, fields []uint8, data []byte) (int, error) {
+	n, _, err := decodeInto(dst, fields, prefixBitsOf(fields), data)
+	return n, err
+}
+
+// Decode a record of 64-bit values, see DecodeInto.
+func DecodeInto64(dst []uint64, fields []uint8, data []byte) (int, error) {
+	n, _, err := decodeInto64(dst, fields, prefixBitsOf(fields), data)
+	return n, err
+}
+
+// Encode the given values, see AppendEncode.
+func (s *Schema) AppendEncode(dst []byte, values []uint32) ([]byte, error) {
+	if err := s.check(32, len(values)); err != nil {
+		return dst, err
+	}
+
+	return appendEncode(dst, s.fields, values)
+}
+
+// Encode the given 64-bit values, see AppendEncode64.
+func (s *Schema) AppendEncode64(dst []byte, values []uint64) ([]byte, error) {
+	if err := s.check(64, len(values)); err != nil {
+		return dst, err
+	}
+
+	return appendEncode64(dst, s.fields, values)
+}
+
+// Decode a record into dst, see DecodeInto.
+func (s *Schema) DecodeInto(dst []uint32, data []byte) (int, error) {
+	if err := s.check(32, len(s.fields)); err != nil {
+		return 0, err
+	}
+
+	n, _, err := decodeInto(dst, s.fields, s.prefixBits, data)
+	return n, err
+}
+
+// Decode a record of 64-bit values into dst, see DecodeInto64.
+func (s *Schema) DecodeInto64(dst []uint64, data []byte) (int, error) {
+	if err := s.check(64, len(s.fields)); err != nil {
+		return 0, err
+	}
+
+	n, _, err := decodeInto64(dst, s.fields, s.prefixBits, data)
+	return n, err
+}
+
+// Return the number of bits taken up by the width prefixes of the fields.
+func prefixBitsOf(fields []uint8) int {
+	prefixBits := 0
+	for _, fieldWidth := range fields {
+		prefixBits += int(fieldWidth &^ SignedField)
+	}
+
+	return prefixBits
+}
+
+// Unlike encode, which builds the width prefixes and the values in separate
+// slices and then merges them, the record is sized up front and written to
+// dst in one go: first all of the width prefixes, then all of the values.
+func appendEncode(dst []byte, fields []uint8, values []uint32) ([]byte, error) {
+	prefixBits, valueBits, err := encodedBits(fields, values)
+	if err != nil {
+		return dst, err
+	}
+
+	dst = grow(dst, (prefixBits+valueBits+7)/8)
+
+	var curByte uint8
+	var curIndex uint8
+
+	for i, fieldWidth := range fields {
+		value := values[i]
+		if fieldWidth&SignedField != 0 {
+			fieldWidth &^= SignedField
+			value = zigzagEncode32(value)
+		}
+
+		addBitsToSlice(&dst, uint32(32-countLeadingZeros(value)), fieldWidth, &curByte, &curIndex, false)
+	}
+
+	for i, fieldWidth := range fields {
+		value := values[i]
+		if fieldWidth&SignedField != 0 {
+			value = zigzagEncode32(value)
+		}
+
+		// The leading bit of the value is implied by its width.
+		addBitsToSlice(&dst, value, uint8(32-countLeadingZeros(value)), &curByte, &curIndex, true)
+	}
+
+	// Add trailing bits.
+	if curIndex > 0 {
+		addBitsToSlice(&dst, 0, 8-curIndex, &curByte, &curIndex, false)
+	}
+
+	return dst, nil
+}
+
+func appendEncode64(dst []byte, fields []uint8, values []uint64) ([]byte, error) {
+	prefixBits, valueBits, err := encodedBits64(fields, values)
+	if err != nil {
+		return dst, err
+	}
+
+	dst = grow(dst, (prefixBits+valueBits+7)/8)
+
+	var curByte uint8
+	var curIndex uint8
+
+	for i, fieldWidth := range fields {
+		value := values[i]
+		if fieldWidth&SignedField != 0 {
+			fieldWidth &^= SignedField
+			value = zigzagEncode64(value)
+		}
+
+		addBitsToSlice(&dst, uint32(64-countLeadingZeros64(value)), fieldWidth, &curByte, &curIndex, false)
+	}
+
+	for i, fieldWidth := range fields {
+		value := values[i]
+		if fieldWidth&SignedField != 0 {
+			value = zigzagEncode64(value)
+		}
+
+		addBitsToSlice64(&dst, value, uint8(64-countLeadingZeros64(value)), &curByte, &curIndex, true)
+	}
+
+	// Add trailing bits.
+	if curIndex > 0 {
+		addBitsToSlice(&dst, 0, 8-curIndex, &curByte, &curIndex, false)
+	}
+
+	return dst, nil
+}
+
+// Return dst with room for at least n more bytes, so that appending them
+// allocates at most once.
+func grow(dst []byte, n int) []byte {
+	if cap(dst)-len(dst) >= n {
+		return dst
+	}
+
+	grown := make([]byte, len(dst), len(dst)+n)
+	copy(grown, dst)
+	return grown
+}
+
+// Decode a record whose width prefixes take up prefixBits bits into dst,
+// returning the number of values and the number of bits read.
+func decodeInto(dst []uint32, fields []uint8, prefixBits int, data []byte) (int, int, error) {
+	if len(dst) < len(fields) {
+		return 0, 0, fmt.Errorf("destination too short, got room for %d values and %d fields", len(dst), len(fields))
+	}
+
+	var u Unpacker
+	if err := u.Reset(data, prefixBits); err != nil {
+		return 0, 0, err
+	}
+
+	for i, fieldWidth := range fields {
+		value, err := u.next(fieldWidth&^SignedField, 32)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		dst[i] = uint32(value)
+		if fieldWidth&SignedField != 0 {
+			dst[i] = zigzagDecode32(dst[i])
+		}
+	}
+
+	return len(fields), u.valuePos, nil
+}
+
+func decodeInto64(dst []uint64, fields []uint8, prefixBits int, data []byte) (int, int, error) {
+	if len(dst) < len(fields) {
+		return 0, 0, fmt.Errorf("destination too short, got room for %d values and %d fields", len(dst), len(fields))
+	}
+
+	var u Unpacker
+	if err := u.Reset(data, prefixBits); err != nil {
+		return 0, 0, err
+	}
+
+	for i, fieldWidth := range fields {
+		value, err := u.next(fieldWidth&^SignedField, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if fieldWidth&SignedField != 0 {
+			value = zigzagDecode64(value)
+		}
+		dst[i] = value
+	}
+
+	return len(fields), u.valuePos, nil
+}
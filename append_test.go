@@ -0,0 +1,190 @@
+package govarint
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+var activityFields = []uint8{3, 3, 6, 3, 6, 3, 6, 6}
+var activityValues = []uint32{0, 4, 628043, 1, 105373071, 8, 235836567, 1429277704}
+
+func TestAppendEncodeMatchesEncode(t *testing.T) {
+	prefix := []byte{0xab}
+
+	for _, tc := range encodeTests {
+		result, err := AppendEncode(prefix[:1:1], tc.fields, tc.values)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if result[0] != 0xab || !bytes.Equal(result[1:], tc.result) {
+			t.Errorf("Expected 0xab%x, got 0x%x for %v", tc.result, result, tc)
+			continue
+		}
+
+		values := make([]uint32, len(tc.fields)+1)
+		n, err := DecodeInto(values, tc.fields, tc.result)
+		if err != nil {
+			t.Errorf("Unexpected decode error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if n != len(tc.fields) {
+			t.Errorf("Expected %d values, got %d for %v", len(tc.fields), n, tc)
+		}
+		for i, expected := range tc.values {
+			if expected != values[i] {
+				t.Errorf("Incorrect value, expected 0x%08x, got 0x%08x for %v", expected, values[i], tc)
+			}
+		}
+	}
+}
+
+func TestAppendEncodeRandom(t *testing.T) {
+	var buf []byte
+	values := make([]uint32, 30)
+	values64 := make([]uint64, 30)
+
+	for testCount := 0; testCount < 100000; testCount++ {
+		valueCount := int(rand.Int31n(30)) + 1
+
+		tc := roundTrip64TestCase{}
+		for i := 0; i < valueCount; i++ {
+			tc.values = append(tc.values, rand.Uint64()&((1<<uint(rand.Int31n(65)))-1))
+			fieldWidth := uint8(7)
+			if rand.Int31n(2) == 0 {
+				fieldWidth = Signed(7)
+			}
+			tc.fields = append(tc.fields, fieldWidth)
+		}
+
+		expected, err := Encode64(tc.fields, tc.values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, tc)
+		}
+
+		buf, err = AppendEncode64(buf[:0], tc.fields, tc.values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, tc)
+		}
+		if !bytes.Equal(buf, expected) {
+			t.Fatalf("Expected 0x%x, got 0x%x for %v", expected, buf, tc)
+		}
+
+		if _, err := DecodeInto64(values64, tc.fields, buf); err != nil {
+			t.Fatalf("Unexpected decode error \"%s\" for %v", err, tc)
+		}
+		for i, expected := range tc.values {
+			if expected != values64[i] {
+				t.Fatalf("Incorrect value, expected 0x%016x, got 0x%016x for %v", expected, values64[i], tc)
+			}
+		}
+
+		// Narrow the values for the 32-bit path.
+		values32 := make([]uint32, valueCount)
+		fields32 := make([]uint8, valueCount)
+		for i, v := range tc.values {
+			values32[i] = uint32(v)
+			fields32[i] = tc.fields[i]&SignedField | 6
+		}
+
+		expected, err = Encode(fields32, values32)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, tc)
+		}
+
+		buf, err = AppendEncode(buf[:0], fields32, values32)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, tc)
+		}
+		if !bytes.Equal(buf, expected) {
+			t.Fatalf("Expected 0x%x, got 0x%x for %v", expected, buf, tc)
+		}
+
+		if _, err := DecodeInto(values, fields32, buf); err != nil {
+			t.Fatalf("Unexpected decode error \"%s\" for %v", err, tc)
+		}
+		for i, expected := range values32 {
+			if expected != values[i] {
+				t.Fatalf("Incorrect value, expected 0x%08x, got 0x%08x for %v", expected, values[i], tc)
+			}
+		}
+	}
+}
+
+func TestAppendEncodeErrors(t *testing.T) {
+	dst := []byte{1, 2}
+
+	result, err := AppendEncode(dst, []uint8{2}, []uint32{8})
+	if err == nil || err.Error() != "value 8 too large for field width 2" {
+		t.Errorf("Expected value too large error, got %v", err)
+	}
+	if !bytes.Equal(result, dst) {
+		t.Errorf("Expected destination to be returned unchanged, got 0x%x", result)
+	}
+
+	if _, err := DecodeInto(make([]uint32, 1), []uint8{4, 5}, []byte{0x17, 0x40, 0xe4}); err == nil {
+		t.Errorf("Expected error for short destination")
+	}
+
+	if _, err := DecodeInto(make([]uint32, 2), []uint8{4, 5}, []byte{0x17, 0x40}); err == nil {
+		t.Errorf("Expected error for truncated data")
+	}
+}
+
+func TestZeroAllocations(t *testing.T) {
+	schema, err := NewSchema(activityFields...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	buf := make([]byte, 0, schema.MaxSize())
+	values := make([]uint32, len(activityFields))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = schema.AppendEncode(buf[:0], activityValues)
+		schema.DecodeInto(values, buf)
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %f per record", allocs)
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		Encode(activityFields, activityValues)
+	}
+}
+
+func BenchmarkAppendEncode(b *testing.B) {
+	b.ReportAllocs()
+	buf := make([]byte, 0, 64)
+
+	for i := 0; i < b.N; i++ {
+		buf, _ = AppendEncode(buf[:0], activityFields, activityValues)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	b.ReportAllocs()
+	data, _ := Encode(activityFields, activityValues)
+
+	for i := 0; i < b.N; i++ {
+		Decode(activityFields, data)
+	}
+}
+
+func BenchmarkDecodeInto(b *testing.B) {
+	b.ReportAllocs()
+	data, _ := Encode(activityFields, activityValues)
+	values := make([]uint32, len(activityFields))
+
+	for i := 0; i < b.N; i++ {
+		DecodeInto(values, activityFields, data)
+	}
+}
@@ -0,0 +1,142 @@
+package govarint
+
+// A BitWriter appends values of arbitrary bit widths to a byte slice, most
+// significant bit first. It is built on the same helpers as Encode and can
+// be used to build other compact formats.
+//
+// The zero value is an empty writer ready to use.
+type BitWriter struct {
+	buf      []byte
+	curByte  uint8
+	curIndex uint8
+}
+
+// Create a writer appending to buf, which must be empty or hold whole
+// bytes written previously.
+func NewBitWriter(buf []byte) *BitWriter {
+	return &BitWriter{buf: buf}
+}
+
+// Write the low width bits of value. The width may be at most 64.
+func (w *BitWriter) WriteBits(value uint64, width uint8) error {
+	if width > 64 {
+		return newError(ErrInvalidWidth, -1, uint64(width), -1, "invalid bit width %d, expected at most 64", width)
+	}
+
+	addBitsToSlice64(&w.buf, value, width, &w.curByte, &w.curIndex, false)
+	return nil
+}
+
+// Pad the output with zero bits up to the next byte boundary.
+func (w *BitWriter) Align() {
+	if w.curIndex > 0 {
+		addBitsToSlice(&w.buf, 0, 8-w.curIndex, &w.curByte, &w.curIndex, false)
+	}
+}
+
+// Return the number of bits written.
+func (w *BitWriter) BitLen() int {
+	return len(w.buf)*8 + int(w.curIndex)
+}
+
+// Return the bytes written, padding a trailing partial byte with zero bits.
+// The result shares memory with the writer when it ends on a byte
+// boundary.
+func (w *BitWriter) Bytes() []byte {
+	if w.curIndex == 0 {
+		return w.buf
+	}
+
+	return append(w.buf[:len(w.buf):len(w.buf)], w.curByte)
+}
+
+// Discard everything written, keeping the underlying buffer.
+func (w *BitWriter) Reset() {
+	w.buf = w.buf[:0]
+	w.curByte = 0
+	w.curIndex = 0
+}
+
+// A BitReader reads values of arbitrary bit widths from a byte slice, the
+// counterpart of BitWriter.
+type BitReader struct {
+	data     []byte
+	rest     []byte
+	curByte  uint8
+	curIndex uint8
+	offset   int
+}
+
+// Create a reader positioned at the start of data.
+func NewBitReader(data []byte) *BitReader {
+	r := &BitReader{data: data}
+	r.rest, r.curByte, r.curIndex = cursorAt(data, 0)
+	return r
+}
+
+// Read a value of the given width, which may be at most 64. Returns an
+// error wrapping ErrTruncated if fewer bits remain.
+func (r *BitReader) ReadBits(width uint8) (uint64, error) {
+	if width > 64 {
+		return 0, newError(ErrInvalidWidth, -1, uint64(width), r.offset, "invalid bit width %d, expected at most 64", width)
+	}
+
+	if int(width) > r.Remaining() {
+		return 0, newError(ErrTruncated, -1, uint64(width), r.offset, "ran out of data, expected %d bits of data, got %d", width, r.Remaining())
+	}
+
+	value, err := popBitsFromSlice64(&r.rest, width, &r.curByte, &r.curIndex, false)
+	if err != nil {
+		return 0, err
+	}
+	r.offset += int(width)
+
+	return value, nil
+}
+
+// Skip to the next byte boundary. The data is a whole number of bytes, so
+// the boundary is never past its end and the seek can not fail.
+func (r *BitReader) Align() {
+	r.Seek((r.offset + 7) / 8 * 8)
+}
+
+// Move to the given bit offset from the start of the data. Returns an error
+// wrapping ErrTruncated if the offset is outside the data.
+func (r *BitReader) Seek(bitOffset int) error {
+	if bitOffset < 0 || bitOffset > r.BitLen() {
+		return newError(ErrTruncated, -1, 0, r.offset, "bit offset %d out of range for %d bits", bitOffset, r.BitLen())
+	}
+
+	r.rest, r.curByte, r.curIndex = cursorAt(r.data, bitOffset)
+	r.offset = bitOffset
+
+	return nil
+}
+
+// Return the current bit offset from the start of the data.
+func (r *BitReader) Offset() int {
+	return r.offset
+}
+
+// Return the number of bits left to read.
+func (r *BitReader) Remaining() int {
+	return r.BitLen() - r.offset
+}
+
+// Return the total number of bits in the data.
+func (r *BitReader) BitLen() int {
+	return len(r.data) * 8
+}
+
+// Return whether the bits of data from the given bit offset on can only be
+// the zero padding of the last byte. Formats where every code contains a 1
+// bit use this to find their end without storing a count: fewer than 8 bits
+// left that are all 0 can not hold another code.
+func onlyPadding(data []byte, bitOffset int) bool {
+	remaining := len(data)*8 - bitOffset
+	if remaining >= 8 {
+		return false
+	}
+
+	return remaining <= 0 || data[len(data)-1]&(0xff>>uint(8-remaining)) == 0
+}
@@ -0,0 +1,184 @@
+package govarint
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+type bitWriterTestCase struct {
+	values []uint64
+	widths []uint8
+	result []byte
+}
+
+type onlyPaddingTestCase struct {
+	data      []byte
+	bitOffset int
+	result    bool
+}
+
+var (
+	bitWriterTests = []bitWriterTestCase{
+		{[]uint64{}, []uint8{}, []byte{}},
+		{[]uint64{1}, []uint8{1}, []byte{0x80}},
+		{[]uint64{0xff}, []uint8{4}, []byte{0xf0}},
+		{[]uint64{1, 0x1234}, []uint8{4, 16}, []byte{0x11, 0x23, 0x40}},
+		{[]uint64{0x0123456789abcdef}, []uint8{64}, []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}},
+		{[]uint64{1, 0x0123456789abcdef}, []uint8{1, 64}, []byte{0x80, 0x91, 0xa2, 0xb3, 0xc4, 0xd5, 0xe6, 0xf7, 0x80}},
+	}
+
+	onlyPaddingTests = []onlyPaddingTestCase{
+		{[]byte{}, 0, true},
+		{[]byte{0x80}, 1, true},
+		{[]byte{0x80}, 8, true},
+		{[]byte{0x81}, 1, false},
+		{[]byte{0x80, 0x00}, 1, false},
+		{[]byte{0x80, 0x00}, 9, true},
+	}
+)
+
+func TestBitWriter(t *testing.T) {
+	for _, tc := range bitWriterTests {
+		var w BitWriter
+		bitLen := 0
+		for i, value := range tc.values {
+			if err := w.WriteBits(value, tc.widths[i]); err != nil {
+				t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			}
+			bitLen += int(tc.widths[i])
+		}
+
+		if w.BitLen() != bitLen {
+			t.Errorf("Expected %d bits, got %d for %v", bitLen, w.BitLen(), tc)
+		}
+
+		if !bytes.Equal(w.Bytes(), tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, w.Bytes(), tc)
+		}
+
+		r := NewBitReader(tc.result)
+		for i, expected := range tc.values {
+			value, err := r.ReadBits(tc.widths[i])
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+				break
+			}
+
+			expected &= 1<<tc.widths[i] - 1
+			if value != expected {
+				t.Errorf("Expected 0x%x, got 0x%x for %v", expected, value, tc)
+			}
+		}
+	}
+}
+
+func TestBitWriterRandom(t *testing.T) {
+	w := NewBitWriter(nil)
+
+	for testCount := 0; testCount < 10000; testCount++ {
+		w.Reset()
+
+		count := int(rand.Int31n(20)) + 1
+		values := make([]uint64, count)
+		widths := make([]uint8, count)
+		for i := range values {
+			widths[i] = uint8(rand.Int31n(65))
+			values[i] = rand.Uint64() & (1<<widths[i] - 1)
+			w.WriteBits(values[i], widths[i])
+		}
+
+		r := NewBitReader(w.Bytes())
+		for i, expected := range values {
+			value, err := r.ReadBits(widths[i])
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\" for %v %v", err, values, widths)
+			}
+			if value != expected {
+				t.Fatalf("Expected 0x%x, got 0x%x for %v %v", expected, value, values, widths)
+			}
+		}
+
+		if r.Remaining() >= 8 {
+			t.Fatalf("Expected less than a byte left, got %d bits", r.Remaining())
+		}
+	}
+}
+
+func TestBitWriterAlign(t *testing.T) {
+	var w BitWriter
+	w.WriteBits(1, 1)
+	w.Align()
+	w.Align()
+	w.WriteBits(0x3, 2)
+
+	if w.BitLen() != 10 {
+		t.Errorf("Expected 10 bits, got %d", w.BitLen())
+	}
+
+	expected := []byte{0x80, 0xc0}
+	if !bytes.Equal(w.Bytes(), expected) {
+		t.Errorf("Expected 0x%x, got 0x%x", expected, w.Bytes())
+	}
+
+	if err := w.WriteBits(0, 65); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+}
+
+func TestBitReaderSeek(t *testing.T) {
+	r := NewBitReader([]byte{0x12, 0x34, 0x56})
+
+	if err := r.Seek(4); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if value, _ := r.ReadBits(8); value != 0x23 {
+		t.Errorf("Expected 0x23, got 0x%x", value)
+	}
+
+	r.Align()
+	if r.Offset() != 16 {
+		t.Errorf("Expected offset 16, got %d", r.Offset())
+	}
+	if value, _ := r.ReadBits(8); value != 0x56 {
+		t.Errorf("Expected 0x56, got 0x%x", value)
+	}
+
+	if _, err := r.ReadBits(1); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if err := r.Seek(25); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated seeking past the end, got %v", err)
+	}
+
+	if err := r.Seek(-1); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated seeking before the start, got %v", err)
+	}
+
+	r.Seek(0)
+	if value, _ := r.ReadBits(24); value != 0x123456 {
+		t.Errorf("Expected 0x123456, got 0x%x", value)
+	}
+}
+
+func TestBitReaderEmpty(t *testing.T) {
+	r := NewBitReader(nil)
+
+	if value, err := r.ReadBits(0); err != nil || value != 0 {
+		t.Errorf("Expected 0, got %d, %v", value, err)
+	}
+
+	if _, err := r.ReadBits(1); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+}
+
+func TestOnlyPadding(t *testing.T) {
+	for _, tc := range onlyPaddingTests {
+		if result := onlyPadding(tc.data, tc.bitOffset); result != tc.result {
+			t.Errorf("Expected %t, got %t for %v", tc.result, result, tc)
+		}
+	}
+}
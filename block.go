@@ -0,0 +1,256 @@
+package govarint
+
+import (
+	"encoding/binary"
+)
+
+// Encode a block of values with frame of reference packing: the smallest
+// value is written once as the base, and every value is stored as its
+// difference from the base packed at one shared bit width, so there are no
+// per-value width prefixes.
+//
+// Differences too wide for the shared width are patched exceptions: their
+// low bits are packed like the others and their high bits follow the packed
+// values. The width is chosen to give the smallest block, so a few outliers
+// do not widen every value.
+//
+// The block is laid out as:
+//
+//	uvarint  number of values n
+//	uvarint  base
+//	byte     shared width b, at least 1 unless n is 0
+//	uvarint  number of exceptions
+//	n*b bits packed values, zero padded to a byte boundary
+//	for each exception, the uvarint number of values since the previous
+//	exception followed by the uvarint high bits
+func EncodeBlock(values []uint32) []byte {
+	values64 := make([]uint64, len(values))
+	for i, value := range values {
+		values64[i] = uint64(value)
+	}
+
+	return encodeBlock(values64)
+}
+
+// Encode a block of 64-bit values, see EncodeBlock.
+func EncodeBlock64(values []uint64) []byte {
+	return encodeBlock(values)
+}
+
+// Decode the block at the start of data, returning its values along with
+// the number of bytes it took up.
+func DecodeBlock(data []byte) ([]uint32, int, error) {
+	values64, n, err := decodeBlock(data, 32)
+	if err != nil {
+		return []uint32{}, 0, err
+	}
+
+	values := make([]uint32, len(values64))
+	for i, value := range values64 {
+		values[i] = uint32(value)
+	}
+
+	return values, n, nil
+}
+
+// Decode the block of 64-bit values at the start of data, see DecodeBlock.
+func DecodeBlock64(data []byte) ([]uint64, int, error) {
+	values, n, err := decodeBlock(data, 64)
+	if err != nil {
+		return []uint64{}, 0, err
+	}
+
+	return values, n, nil
+}
+
+func encodeBlock(values []uint64) []byte {
+	base := uint64(0)
+	if len(values) > 0 {
+		base = values[0]
+	}
+	for _, value := range values {
+		if value < base {
+			base = value
+		}
+	}
+
+	maxWidth := 1
+	for _, value := range values {
+		if width := 64 - countLeadingZeros64(value-base); width > maxWidth {
+			maxWidth = width
+		}
+	}
+
+	width := blockWidth(values, base, maxWidth)
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	data := make([]byte, 0, 3*binary.MaxVarintLen64+1+(len(values)*width+7)/8)
+	data = append(data, buf[:binary.PutUvarint(buf, uint64(len(values)))]...)
+	data = append(data, buf[:binary.PutUvarint(buf, base)]...)
+	if len(values) == 0 {
+		return append(data, 0, 0)
+	}
+	data = append(data, uint8(width))
+
+	exceptionCount := 0
+	for _, value := range values {
+		if (value-base)>>uint(width) != 0 {
+			exceptionCount++
+		}
+	}
+	data = append(data, buf[:binary.PutUvarint(buf, uint64(exceptionCount))]...)
+
+	var curByte uint8
+	var curIndex uint8
+	for _, value := range values {
+		addBitsToSlice64(&data, value-base, uint8(width), &curByte, &curIndex, false)
+	}
+
+	// Add trailing packed bits.
+	if curIndex > 0 {
+		addBitsToSlice(&data, 0, 8-curIndex, &curByte, &curIndex, false)
+	}
+
+	last := -1
+	for i, value := range values {
+		if high := (value - base) >> uint(width); high != 0 {
+			data = append(data, buf[:binary.PutUvarint(buf, uint64(i-last-1))]...)
+			data = append(data, buf[:binary.PutUvarint(buf, high)]...)
+			last = i
+		}
+	}
+
+	return data
+}
+
+// Return the shared width from 1 to maxWidth giving the smallest block.
+func blockWidth(values []uint64, base uint64, maxWidth int) int {
+	bestWidth := maxWidth
+	bestBits := -1
+	for width := 1; width <= maxWidth; width++ {
+		bits := (len(values)*width + 7) / 8 * 8
+
+		exceptionCount := 0
+		last := -1
+		for i, value := range values {
+			if high := (value - base) >> uint(width); high != 0 {
+				bits += 8 * (uvarintLen(uint64(i-last-1)) + uvarintLen(high))
+				exceptionCount++
+				last = i
+			}
+		}
+		bits += 8 * uvarintLen(uint64(exceptionCount))
+
+		if bestBits < 0 || bits < bestBits {
+			bestWidth = width
+			bestBits = bits
+		}
+	}
+
+	return bestWidth
+}
+
+// Return the number of bytes binary.PutUvarint writes for x.
+func uvarintLen(x uint64) int {
+	n := 1
+	for ; x >= 0x80; x >>= 7 {
+		n++
+	}
+
+	return n
+}
+
+// Decode a block holding values of at most valueBits bits.
+func decodeBlock(data []byte, valueBits int) ([]uint64, int, error) {
+	if len(data) == 0 {
+		return nil, 0, ErrEmpty
+	}
+
+	offset := 0
+	readUvarint := func(what string) (uint64, error) {
+		value, n := binary.Uvarint(data[offset:])
+		if n <= 0 {
+			return 0, newError(ErrTruncated, -1, 0, offset*8, "ran out of data before end of %s", what)
+		}
+
+		offset += n
+		return value, nil
+	}
+
+	count, err := readUvarint("value count")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	base, err := readUvarint("base")
+	if err != nil {
+		return nil, 0, err
+	}
+	if base>>uint(valueBits-1)>>1 != 0 {
+		return nil, 0, newError(ErrValueTooLarge, -1, base, (offset-uvarintLen(base))*8, "base %d too large for %d-bit values", base, valueBits)
+	}
+
+	if offset >= len(data) {
+		return nil, 0, newError(ErrTruncated, -1, 0, offset*8, "ran out of data before end of width")
+	}
+	width := int(data[offset])
+	if width > valueBits || (width == 0 && count > 0) {
+		return nil, 0, newError(ErrInvalidWidth, -1, uint64(width), offset*8, "invalid block width %d for %d-bit values", width, valueBits)
+	}
+	offset++
+
+	exceptionCount, err := readUvarint("exception count")
+	if err != nil {
+		return nil, 0, err
+	}
+	if exceptionCount > count {
+		return nil, 0, newError(ErrValueTooLarge, -1, exceptionCount, offset*8, "exception count %d too large for %d values", exceptionCount, count)
+	}
+
+	// Every value takes up at least one bit, so a count larger than that
+	// allows cannot be right and must not be allocated for.
+	totalBits := (len(data) - offset) * 8
+	if count > uint64(totalBits) || int(count)*width > totalBits {
+		return nil, 0, newError(ErrTruncated, -1, count, offset*8, "ran out of data before end of packed values, expected %d values of %d bits, got %d bits of data", count, width, totalBits)
+	}
+
+	values := make([]uint64, count)
+	for i := range values {
+		values[i] = getBits(data[offset:], i*width, uint(width))
+	}
+	offset += (int(count)*width + 7) / 8
+
+	last := -1
+	for e := uint64(0); e < exceptionCount; e++ {
+		gap, err := readUvarint("exception")
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if gap >= count-uint64(last+1) {
+			return nil, 0, newError(ErrValueTooLarge, -1, gap, offset*8, "exception gap %d out of range for %d values", gap, count)
+		}
+		i := last + 1 + int(gap)
+
+		high, err := readUvarint("exception")
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if 64-countLeadingZeros64(high)+width > valueBits {
+			return nil, 0, newError(ErrValueTooLarge, i, high, offset*8, "exception %d at index %d too large for %d-bit values", high, i, valueBits)
+		}
+
+		values[i] |= high << uint(width)
+		last = i
+	}
+
+	for i, value := range values {
+		if value > (^uint64(0)>>uint(64-valueBits))-base {
+			return nil, 0, newError(ErrValueTooLarge, i, value, -1, "value %d at index %d overflows base %d", value, i, base)
+		}
+		values[i] = value + base
+	}
+
+	return values, offset, nil
+}
@@ -0,0 +1,133 @@
+package govarint
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type blockTestCase struct {
+	values []uint32
+	result []byte
+}
+
+var (
+	blockTests = []blockTestCase{
+		{[]uint32{}, []byte{0x00, 0x00, 0x00, 0x00}},
+		{[]uint32{7}, []byte{0x01, 0x07, 0x01, 0x00, 0x00}},
+		{[]uint32{100, 101, 103, 100}, []byte{0x04, 0x64, 0x02, 0x00, 0x1c}},
+		// The outlier is an exception rather than widening every value.
+		{[]uint32{1, 2, 3, 1, 2, 3, 1, 2, 1000, 1, 2, 3}, []byte{0x0c, 0x01, 0x02, 0x01, 0x18, 0x61, 0xc6, 0x08, 0xf9, 0x01}},
+	}
+)
+
+func TestEncodeBlock(t *testing.T) {
+	for _, tc := range blockTests {
+		result := EncodeBlock(tc.values)
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+
+		values, n, err := DecodeBlock(append(result, 0xff))
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if n != len(result) {
+			t.Errorf("Expected %d bytes read, got %d for %v", len(result), n, tc)
+		}
+
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("Expected %v, got %v for %v", tc.values, values, tc)
+		}
+	}
+}
+
+func TestBlockRoundTrip(t *testing.T) {
+	for testCount := 0; testCount < 10000; testCount++ {
+		values := make([]uint64, rand.Int31n(200))
+		base := rand.Uint64() >> uint(rand.Int31n(65)) >> 1
+		width := uint(rand.Int31n(20))
+		for i := range values {
+			values[i] = base + rand.Uint64()&(1<<width-1)
+			// Occasional outliers, up to the largest value.
+			if rand.Int31n(20) == 0 {
+				values[i] = base + rand.Uint64()&(^uint64(0)-base)
+			}
+		}
+
+		data := EncodeBlock64(values)
+		result, n, err := DecodeBlock64(data)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		if n != len(data) {
+			t.Fatalf("Expected %d bytes read, got %d for %v", len(data), n, values)
+		}
+
+		if !reflect.DeepEqual(result, values) {
+			t.Fatalf("Expected %v, got %v", values, result)
+		}
+	}
+}
+
+// Blocks of similar values are smaller than records with a width prefix
+// per value.
+func TestBlockSize(t *testing.T) {
+	values := make([]uint32, 1000)
+	for i := range values {
+		values[i] = 1000000 + uint32(rand.Int31n(1000))
+		if rand.Int31n(100) == 0 {
+			values[i] = uint32(rand.Int31())
+		}
+	}
+
+	block := EncodeBlock(values)
+	record, _ := Encode(deltaFields(6, len(values)), values)
+	if len(block) >= len(record) {
+		t.Errorf("Expected block smaller than %d bytes, got %d", len(record), len(block))
+	}
+}
+
+func TestDecodeBlockErrors(t *testing.T) {
+	if _, _, err := DecodeBlock([]byte{}); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+
+	if _, _, err := DecodeBlock([]byte{0x01, 0x07}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if _, _, err := DecodeBlock([]byte{0x01, 0x00, 0x21, 0x00, 0x00}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+
+	if _, _, err := DecodeBlock([]byte{0x01, 0x00, 0x00, 0x00}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+
+	// A count far beyond what the data could hold.
+	if _, _, err := DecodeBlock([]byte{0xff, 0xff, 0xff, 0xff, 0x0f, 0x00, 0x01, 0x00, 0x00}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	// An exception past the last value.
+	if _, _, err := DecodeBlock([]byte{0x01, 0x00, 0x01, 0x01, 0x00, 0x01, 0x01}); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	}
+
+	// Base plus value overflowing 32 bits.
+	data := EncodeBlock64([]uint64{1 << 32, 1<<32 + 1})
+	if _, _, err := DecodeBlock(data); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	}
+
+	data = EncodeBlock64([]uint64{0xffffffff, 0})
+	if _, _, err := DecodeBlock(data); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,347 @@
+// Command govarint-gen generates MarshalGovarint and UnmarshalGovarint
+// methods for struct types whose fields carry govarint struct tags, so that
+// records can be packed without reflection.
+//
+// It is meant to be run by go generate, e.g.:
+//
+//	//go:generate govarint-gen -type Activity
+//
+// For an input file activity.go the methods are written to
+// activity_govarint.go and round-trip tests to activity_govarint_test.go.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const importPath = "github.com/500px/govarint"
+
+// A tagged struct field.
+type field struct {
+	name   string
+	kind   string
+	width  uint8
+	bits   int
+	signed bool
+}
+
+// A struct type to generate methods for.
+type structType struct {
+	name   string
+	fields []field
+}
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of type names; defaults to every struct with govarint tags")
+	output := flag.String("output", "", "output file name; defaults to <file>_govarint.go")
+	tests := flag.Bool("tests", true, "also generate round-trip tests")
+	flag.Parse()
+
+	filename := flag.Arg(0)
+	if filename == "" {
+		filename = os.Getenv("GOFILE")
+	}
+	if filename == "" || flag.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "usage: govarint-gen [-type T,U] [-output file] [-tests=false] [file.go]")
+		os.Exit(2)
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+
+	if err := run(filename, *output, types, *tests); err != nil {
+		fmt.Fprintf(os.Stderr, "govarint-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(filename string, output string, types []string, tests bool) error {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	code, testCode, err := generate(filename, src, types)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(filename, ".go") + "_govarint.go"
+	}
+
+	if err := ioutil.WriteFile(output, code, 0644); err != nil {
+		return err
+	}
+
+	if !tests {
+		return nil
+	}
+
+	return ioutil.WriteFile(strings.TrimSuffix(output, ".go")+"_test.go", testCode, 0644)
+}
+
+// Return the generated methods and tests for the given source file.
+func generate(filename string, src []byte, types []string) ([]byte, []byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	structs, err := findStructs(file, types)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	code := &bytes.Buffer{}
+	fmt.Fprintf(code, "// Code generated by govarint-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(code, "package %s\n\n", file.Name.Name)
+	fmt.Fprintf(code, "import %q\n", importPath)
+	for _, s := range structs {
+		writeMethods(code, s)
+	}
+
+	testCode := &bytes.Buffer{}
+	fmt.Fprintf(testCode, "// Code generated by govarint-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(testCode, "package %s\n\n", file.Name.Name)
+	fmt.Fprintf(testCode, "import \"testing\"\n")
+	for _, s := range structs {
+		writeTest(testCode, s)
+	}
+
+	formatted, err := format.Source(code.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("formatting generated code: %s", err)
+	}
+
+	formattedTest, err := format.Source(testCode.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("formatting generated tests: %s", err)
+	}
+
+	return formatted, formattedTest, nil
+}
+
+// Return the struct types declared in the file that have govarint tags, or
+// only the named ones if types is not empty.
+func findStructs(file *ast.File, types []string) ([]structType, error) {
+	wanted := map[string]bool{}
+	for _, name := range types {
+		wanted[strings.TrimSpace(name)] = true
+	}
+
+	structs := []structType{}
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			structSpec, ok := typeSpec.Type.(*ast.StructType)
+			if !ok || (len(wanted) > 0 && !wanted[typeSpec.Name.Name]) {
+				continue
+			}
+
+			s, err := parseStruct(typeSpec.Name.Name, structSpec)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(s.fields) == 0 {
+				if wanted[s.name] {
+					return nil, fmt.Errorf("type %s has no govarint tags", s.name)
+				}
+				continue
+			}
+
+			delete(wanted, s.name)
+			structs = append(structs, s)
+		}
+	}
+
+	for name := range wanted {
+		return nil, fmt.Errorf("struct type %s not found", name)
+	}
+
+	if len(structs) == 0 {
+		return nil, fmt.Errorf("no struct types with govarint tags found")
+	}
+
+	return structs, nil
+}
+
+// Bit sizes of the supported field types.
+var kindBits = map[string]int{
+	"bool":   1,
+	"uint":   64,
+	"uint8":  8,
+	"byte":   8,
+	"uint16": 16,
+	"uint32": 32,
+	"uint64": 64,
+	"int":    64,
+	"int8":   8,
+	"int16":  16,
+	"int32":  32,
+	"int64":  64,
+}
+
+func parseStruct(name string, structSpec *ast.StructType) (structType, error) {
+	s := structType{name: name}
+
+	for _, f := range structSpec.Fields.List {
+		if f.Tag == nil {
+			continue
+		}
+
+		tagValue, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return s, err
+		}
+
+		tag, ok := reflect.StructTag(tagValue).Lookup("govarint")
+		if !ok {
+			continue
+		}
+
+		width, err := strconv.ParseUint(tag, 10, 8)
+		if err != nil || width == 0 || width > 7 {
+			return s, fmt.Errorf("invalid govarint tag %q in %s, expected a width from 1 to 7", tag, name)
+		}
+
+		ident, ok := f.Type.(*ast.Ident)
+		if !ok || kindBits[ident.Name] == 0 {
+			return s, fmt.Errorf("unsupported type for tagged field in %s, expected a bool or integer type", name)
+		}
+
+		if len(f.Names) == 0 {
+			return s, fmt.Errorf("embedded field %s in %s cannot be tagged", ident.Name, name)
+		}
+
+		for _, fieldName := range f.Names {
+			s.fields = append(s.fields, field{
+				name:   fieldName.Name,
+				kind:   ident.Name,
+				width:  uint8(width),
+				bits:   kindBits[ident.Name],
+				signed: strings.HasPrefix(ident.Name, "int"),
+			})
+		}
+	}
+
+	return s, nil
+}
+
+func writeMethods(w *bytes.Buffer, s structType) {
+	recv := strings.ToLower(s.name[:1])
+
+	prefixBits := 0
+	for _, f := range s.fields {
+		prefixBits += int(f.width)
+	}
+
+	fmt.Fprintf(w, "\n// MarshalGovarint encodes the tagged fields of %s in the govarint format.\n", s.name)
+	fmt.Fprintf(w, "func (%s *%s) MarshalGovarint() ([]byte, error) {\n", recv, s.name)
+	fmt.Fprintf(w, "var p govarint.Packer\n")
+	for _, f := range s.fields {
+		switch {
+		case f.kind == "bool":
+			fmt.Fprintf(w, "if err := p.PutBool(%d, %s.%s); err != nil {\n", f.width, recv, f.name)
+		case f.signed:
+			fmt.Fprintf(w, "if err := p.PutInt(%d, int64(%s.%s)); err != nil {\n", f.width, recv, f.name)
+		default:
+			fmt.Fprintf(w, "if err := p.PutUint(%d, uint64(%s.%s)); err != nil {\n", f.width, recv, f.name)
+		}
+		fmt.Fprintf(w, "return nil, err\n}\n")
+	}
+	fmt.Fprintf(w, "return p.Bytes(), nil\n}\n")
+
+	fmt.Fprintf(w, "\n// UnmarshalGovarint decodes a record produced by MarshalGovarint.\n")
+	fmt.Fprintf(w, "func (%s *%s) UnmarshalGovarint(data []byte) error {\n", recv, s.name)
+	fmt.Fprintf(w, "var u govarint.Unpacker\n")
+	fmt.Fprintf(w, "if err := u.Reset(data, %d); err != nil {\nreturn err\n}\n", prefixBits)
+	for _, f := range s.fields {
+		fmt.Fprintf(w, "{\n")
+		switch {
+		case f.kind == "bool":
+			fmt.Fprintf(w, "v, err := u.Bool(%d)\n", f.width)
+		case f.signed:
+			fmt.Fprintf(w, "v, err := u.Int(%d, %d)\n", f.width, f.bits)
+		default:
+			fmt.Fprintf(w, "v, err := u.Uint(%d, %d)\n", f.width, f.bits)
+		}
+		fmt.Fprintf(w, "if err != nil {\nreturn err\n}\n")
+		if f.kind == "bool" {
+			fmt.Fprintf(w, "%s.%s = v\n", recv, f.name)
+		} else {
+			fmt.Fprintf(w, "%s.%s = %s(v)\n", recv, f.name, f.kind)
+		}
+		fmt.Fprintf(w, "}\n")
+	}
+	fmt.Fprintf(w, "return nil\n}\n")
+}
+
+func writeTest(w *bytes.Buffer, s structType) {
+	fmt.Fprintf(w, "\nfunc Test%sGovarintRoundTrip(t *testing.T) {\n", s.name)
+	fmt.Fprintf(w, "for _, v := range []%s{\n{},\n", s.name)
+	for _, extreme := range []bool{false, true} {
+		fmt.Fprintf(w, "{\n")
+		for _, f := range s.fields {
+			fmt.Fprintf(w, "%s: %s,\n", f.name, sampleValue(f, extreme))
+		}
+		fmt.Fprintf(w, "},\n")
+	}
+	fmt.Fprintf(w, "} {\n")
+	fmt.Fprintf(w, "data, err := v.MarshalGovarint()\n")
+	fmt.Fprintf(w, "if err != nil {\nt.Fatalf(\"Unexpected error: %%s\", err)\n}\n")
+	fmt.Fprintf(w, "var result %s\n", s.name)
+	fmt.Fprintf(w, "if err := result.UnmarshalGovarint(data); err != nil {\nt.Fatalf(\"Unexpected error: %%s\", err)\n}\n")
+	for _, f := range s.fields {
+		fmt.Fprintf(w, "if result.%s != v.%s {\n", f.name, f.name)
+		fmt.Fprintf(w, "t.Errorf(\"Expected %s %%v, got %%v\", v.%s, result.%s)\n}\n", f.name, f.name, f.name)
+	}
+	fmt.Fprintf(w, "}\n}\n")
+}
+
+// Return a Go expression for the largest value a field can hold, or for
+// signed fields the smallest value if extreme is set. Unsigned fields use 1
+// when extreme is set.
+func sampleValue(f field, extreme bool) string {
+	if f.kind == "bool" {
+		return "true"
+	}
+
+	valueBits := (1 << f.width) - 1
+	if valueBits > f.bits {
+		valueBits = f.bits
+	}
+
+	if !f.signed {
+		if extreme {
+			return "1"
+		}
+		return strconv.FormatUint(1<<uint(valueBits)-1, 10)
+	}
+
+	// Zigzag mapping halves the range available to each sign.
+	if extreme {
+		return fmt.Sprintf("%s(%d)", f.kind, -1<<uint(valueBits-1))
+	}
+	return fmt.Sprintf("%s(%d)", f.kind, 1<<uint(valueBits-1)-1)
+}
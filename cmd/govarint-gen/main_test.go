@@ -0,0 +1,148 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const activitySource = `package feed
+
+type Activity struct {
+	Version    uint8  ` + "`govarint:\"3\"`" + `
+	ActorID    uint32 ` + "`govarint:\"6\"`" + `
+	Delta      int16  ` + "`govarint:\"5\"`" + `
+	Deleted    bool   ` + "`govarint:\"1\"`" + `
+	Name       string
+}
+
+type Untagged struct {
+	Name string
+}
+`
+
+type generateErrorTestCase struct {
+	src   string
+	types []string
+	err   string
+}
+
+var (
+	generateErrorTests = []generateErrorTestCase{
+		{activitySource, []string{"Missing"}, "struct type Missing not found"},
+		{activitySource, []string{"Untagged"}, "type Untagged has no govarint tags"},
+		{"package feed\n\ntype A struct {\n\tX uint8 `govarint:\"8\"`\n}\n", nil, "invalid govarint tag \"8\" in A, expected a width from 1 to 7"},
+		{"package feed\n\ntype A struct {\n\tX float32 `govarint:\"3\"`\n}\n", nil, "unsupported type for tagged field in A, expected a bool or integer type"},
+		{"package feed\n\ntype A struct {\n\tX string\n}\n", nil, "no struct types with govarint tags found"},
+	}
+)
+
+func TestGenerate(t *testing.T) {
+	code, testCode, err := generate("activity.go", []byte(activitySource), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, expected := range []string{
+		"// Code generated by govarint-gen. DO NOT EDIT.",
+		"package feed",
+		"func (a *Activity) MarshalGovarint() ([]byte, error) {",
+		"p.PutUint(3, uint64(a.Version))",
+		"p.PutInt(5, int64(a.Delta))",
+		"p.PutBool(1, a.Deleted)",
+		"func (a *Activity) UnmarshalGovarint(data []byte) error {",
+		"u.Reset(data, 15)",
+		"v, err := u.Uint(6, 32)",
+		"a.Delta = int16(v)",
+	} {
+		if !strings.Contains(string(code), expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", expected, code)
+		}
+	}
+
+	if strings.Contains(string(code), "Untagged") || strings.Contains(string(code), "reflect") {
+		t.Errorf("Unexpected content in generated code:\n%s", code)
+	}
+
+	for _, expected := range []string{
+		"func TestActivityGovarintRoundTrip(t *testing.T) {",
+		"Version: 127,",
+		"ActorID: 4294967295,",
+		"Delta:   int16(-32768),",
+	} {
+		if !strings.Contains(string(testCode), expected) {
+			t.Errorf("Expected generated test to contain %q, got:\n%s", expected, testCode)
+		}
+	}
+}
+
+// Build the generated code against this checkout of govarint in a scratch
+// GOPATH and run the generated round-trip tests.
+func TestGenerateRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of generated code in short mode")
+	}
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	code, testCode, err := generate("activity.go", []byte(activitySource), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	gopath, err := ioutil.TempDir("", "govarint-gen")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(gopath)
+
+	pkg := filepath.Join(gopath, "src", "feed")
+	vendor := filepath.Join(gopath, "src", filepath.FromSlash(importPath))
+	for _, dir := range []string{pkg, filepath.Dir(vendor)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if err := os.Symlink(root, vendor); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for name, src := range map[string][]byte{
+		"activity.go":               []byte(activitySource),
+		"activity_govarint.go":      code,
+		"activity_govarint_test.go": testCode,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(pkg, name), src, 0644); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	cmd := exec.Command(goTool, "test", "-run", "GovarintRoundTrip", ".")
+	cmd.Dir = pkg
+	cmd.Env = append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOFLAGS=")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Generated code failed to build or pass its tests: %s\n%s", err, output)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, tc := range generateErrorTests {
+		_, _, err := generate("test.go", []byte(tc.src), tc.types)
+		if err == nil {
+			t.Errorf("Expected error \"%s\", received no error for %v", tc.err, tc.types)
+		} else if err.Error() != tc.err {
+			t.Errorf("Expected error \"%s\", got: %s", tc.err, err)
+		}
+	}
+}
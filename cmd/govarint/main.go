@@ -0,0 +1,274 @@
+// Command govarint encodes, decodes and inspects govarint records.
+//
+// Usage:
+//
+//	govarint encode  -fields 3,3,6 [-format hex|base64] [file]
+//	govarint decode  -fields 3,3,6 [-format hex|base64] [file]
+//	govarint inspect -fields 3,3,6 [-format hex|base64] [file]
+//
+// Field widths are given as a comma-separated list, with an "s" suffix for
+// signed fields (e.g. 3,5s,6), or read from a schema file listing them in the
+// same form with -schema. Input is read from the named file or from stdin,
+// one record per line. encode reads whitespace-separated decimal values and
+// writes encoded records, decode and inspect read encoded records. inspect
+// prints an annotated map of the bits of each record.
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/500px/govarint"
+)
+
+const usage = "usage: govarint encode|decode|inspect [-fields 3,3,6 | -schema file] [-format hex|base64] [file]"
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "govarint: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// A subcommand reads records from r and writes its results to w.
+type command func(fields []uint8, format string, r io.Reader, w io.Writer) error
+
+var commands = map[string]command{
+	"encode":  encode,
+	"decode":  decode,
+	"inspect": inspect,
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	fieldList := flags.String("fields", "", "comma-separated field widths, with an s suffix for signed fields")
+	schemaFile := flags.String("schema", "", "file listing the field widths")
+	format := flags.String("format", "hex", "encoding of records, hex or base64")
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%s\n%s", err, usage)
+	}
+
+	if *format != "hex" && *format != "base64" {
+		return fmt.Errorf("unknown format %q, expected hex or base64", *format)
+	}
+
+	if (*fieldList == "") == (*schemaFile == "") {
+		return fmt.Errorf("exactly one of -fields and -schema is required\n%s", usage)
+	}
+
+	if *schemaFile != "" {
+		schema, err := ioutil.ReadFile(*schemaFile)
+		if err != nil {
+			return err
+		}
+		*fieldList = string(schema)
+	}
+
+	fields, err := parseFields(*fieldList)
+	if err != nil {
+		return err
+	}
+
+	in := stdin
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	default:
+		return errors.New(usage)
+	}
+
+	out := bufio.NewWriter(stdout)
+	if err := cmd(fields, *format, in, out); err != nil {
+		out.Flush()
+		return err
+	}
+
+	return out.Flush()
+}
+
+// Parse field widths separated by commas or whitespace. Lines starting with
+// # are ignored so that schema files can carry comments.
+func parseFields(s string) ([]uint8, error) {
+	fields := []uint8{}
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		for _, name := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		}) {
+			signed := strings.HasSuffix(name, "s")
+			width, err := strconv.ParseUint(strings.TrimSuffix(name, "s"), 10, 8)
+			if err != nil || width < 1 || width > 7 {
+				return nil, fmt.Errorf("invalid field width %q, expected a width from 1 to 7", name)
+			}
+
+			if signed {
+				fields = append(fields, govarint.Signed(uint8(width)))
+			} else {
+				fields = append(fields, uint8(width))
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("no field widths given")
+	}
+
+	return fields, nil
+}
+
+// Call fn with each non-blank line of r.
+func eachLine(r io.Reader, fn func(line string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if err := fn(line); err != nil {
+			return fmt.Errorf("line %d: %s", lineNumber, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func encode(fields []uint8, format string, r io.Reader, w io.Writer) error {
+	return eachLine(r, func(line string) error {
+		words := strings.Fields(line)
+		if len(words) != len(fields) {
+			return fmt.Errorf("expected %d values, got %d", len(fields), len(words))
+		}
+
+		values := make([]uint64, len(words))
+		for i, word := range words {
+			value, err := parseValue(word, fields[i])
+			if err != nil {
+				return err
+			}
+			values[i] = value
+		}
+
+		data, err := govarint.Encode64(fields, values)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, formatRecord(data, format))
+		return err
+	})
+}
+
+func decode(fields []uint8, format string, r io.Reader, w io.Writer) error {
+	return eachLine(r, func(line string) error {
+		data, err := parseRecord(format, line)
+		if err != nil {
+			return err
+		}
+
+		values, err := govarint.DecodeStrict64(fields, data)
+		if err != nil {
+			return err
+		}
+
+		words := make([]string, len(values))
+		for i, value := range values {
+			words[i] = formatValue(value, fields[i])
+		}
+
+		_, err = fmt.Fprintln(w, strings.Join(words, " "))
+		return err
+	})
+}
+
+func inspect(fields []uint8, format string, r io.Reader, w io.Writer) error {
+	record := 0
+	return eachLine(r, func(line string) error {
+		data, err := parseRecord(format, line)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "record %d: %d bytes\n", record, len(data))
+		record++
+
+		return govarint.Dump(w, fields, data)
+	})
+}
+
+// Parse a decimal value for the given field. Signed fields accept negative
+// values, which are passed on as their two's complement.
+func parseValue(word string, fieldWidth uint8) (uint64, error) {
+	if fieldWidth&govarint.SignedField != 0 {
+		value, err := strconv.ParseInt(word, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q", word)
+		}
+		return uint64(value), nil
+	}
+
+	value, err := strconv.ParseUint(word, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", word)
+	}
+	return value, nil
+}
+
+func formatValue(value uint64, fieldWidth uint8) string {
+	if fieldWidth&govarint.SignedField != 0 {
+		return strconv.FormatInt(int64(value), 10)
+	}
+	return strconv.FormatUint(value, 10)
+}
+
+func formatRecord(data []byte, format string) string {
+	if format == "base64" {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	return hex.EncodeToString(data)
+}
+
+// Parse a single hex or base64 record.
+func parseRecord(format string, line string) ([]byte, error) {
+	var data []byte
+	var err error
+	if format == "base64" {
+		data, err = base64.StdEncoding.DecodeString(line)
+	} else {
+		data, err = hex.DecodeString(line)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s record: %s", format, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type runTestCase struct {
+	args   []string
+	input  string
+	output string
+}
+
+type runErrorTestCase struct {
+	args  []string
+	input string
+	err   string
+}
+
+var (
+	runTests = []runTestCase{
+		{[]string{"encode", "-fields", "1"}, "1\n", "80\n"},
+		{[]string{"encode", "-fields", "3,3,6,3,6"}, "1 5 1128411 2 123456789\n\n7 0 0 0 0\n", "2d54da26fb6d6f3454\n600006\n"},
+		{[]string{"encode", "-fields", "3s", "-format", "base64"}, "-1\n", "IA==\n"},
+		{[]string{"decode", "-fields", "3,3,6,3,6"}, "2d54da26fb6d6f3454\n", "1 5 1128411 2 123456789\n"},
+		{[]string{"decode", "-fields", "3s", "-format", "base64"}, "IA==\n", "-1\n"},
+		{[]string{"inspect", "-fields", "2,5s"}, "42\n", "record 0: 1 bytes\n   0  01     field 0 width prefix 1\n   2  00001  field 1 width prefix 1\n   7  [1]    field 0 value 1\n   7  [1]    field 1 value -1, zigzag 1\n   7  0      padding\n"},
+	}
+
+	runErrorTests = []runErrorTestCase{
+		{[]string{}, "", "usage: govarint"},
+		{[]string{"frobnicate"}, "", "unknown command \"frobnicate\""},
+		{[]string{"encode"}, "", "exactly one of -fields and -schema is required"},
+		{[]string{"encode", "-fields", "8"}, "", "invalid field width \"8\", expected a width from 1 to 7"},
+		{[]string{"encode", "-fields", "3", "-format", "octal"}, "", "unknown format \"octal\", expected hex or base64"},
+		{[]string{"encode", "-fields", "2"}, "8\n", "line 1: value 8 too large for field width 2"},
+		{[]string{"encode", "-fields", "2,2"}, "1\n", "line 1: expected 2 values, got 1"},
+		{[]string{"encode", "-fields", "2"}, "\n-1\n", "line 2: invalid value \"-1\""},
+		{[]string{"decode", "-fields", "3"}, "zz\n", "line 1: invalid hex record"},
+		{[]string{"decode", "-fields", "3"}, "6001\n", "line 1: trailing data"},
+	}
+)
+
+func TestRun(t *testing.T) {
+	for _, tc := range runTests {
+		out := &bytes.Buffer{}
+		if err := run(tc.args, strings.NewReader(tc.input), out); err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if out.String() != tc.output {
+			t.Errorf("Expected %q, got %q for %v", tc.output, out.String(), tc)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, tc := range runErrorTests {
+		err := run(tc.args, strings.NewReader(tc.input), ioutil.Discard)
+		if err == nil {
+			t.Errorf("Expected error for %v", tc)
+			continue
+		}
+
+		if !strings.HasPrefix(err.Error(), tc.err) {
+			t.Errorf("Expected error starting with %q, got %q for %v", tc.err, err, tc)
+		}
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "govarint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	schemaFile := filepath.Join(dir, "schema")
+	inputFile := filepath.Join(dir, "input")
+	ioutil.WriteFile(schemaFile, []byte("# version, delta\n3\n5s\n"), 0644)
+	ioutil.WriteFile(inputFile, []byte("2 -3\n"), 0644)
+
+	out := &bytes.Buffer{}
+	if err := run([]string{"encode", "-schema", schemaFile, inputFile}, nil, out); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	ioutil.WriteFile(inputFile, out.Bytes(), 0644)
+
+	out.Reset()
+	if err := run([]string{"decode", "-schema", schemaFile, inputFile}, nil, out); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if out.String() != "2 -3\n" {
+		t.Errorf("Expected \"2 -3\\n\", got %q", out.String())
+	}
+}
@@ -0,0 +1,165 @@
+package govarint
+
+import (
+	"encoding/binary"
+)
+
+// Encode a non-decreasing sequence of values as the first value followed by
+// the differences between successive values. The number of values is
+// written first as a uvarint, then a record packed like Encode does, with a
+// 6-bit field for the first value so that it can hold any 32-bit value and
+// a field of the given width, at most 6, for each delta.
+//
+// Small gaps between sorted IDs take up far fewer bits than the IDs
+// themselves, so a narrow field width is usually enough for the deltas.
+func EncodeDeltas(fieldWidth uint8, values []uint32) ([]byte, error) {
+	if err := validateDeltaWidth(fieldWidth, 6); err != nil {
+		return []byte{}, err
+	}
+
+	deltas := make([]uint32, len(values))
+	for i, value := range values {
+		if i == 0 {
+			deltas[i] = value
+			continue
+		}
+
+		if value < values[i-1] {
+			return []byte{}, newError(ErrNotSorted, i, uint64(value), -1, "value %d at index %d is lower than the previous value %d", value, i, values[i-1])
+		}
+		deltas[i] = value - values[i-1]
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	data := buf[:binary.PutUvarint(buf, uint64(len(values)))]
+
+	return appendEncode(data, deltaRecordFields(6, fieldWidth, len(values)), deltas)
+}
+
+// Encode a non-decreasing sequence of 64-bit values, see EncodeDeltas. The
+// first value gets a 7-bit field and deltas may be up to 7 bits wide, so the
+// result can only be decoded with DecodeDeltas64.
+func EncodeDeltas64(fieldWidth uint8, values []uint64) ([]byte, error) {
+	if err := validateDeltaWidth(fieldWidth, 7); err != nil {
+		return []byte{}, err
+	}
+
+	deltas := make([]uint64, len(values))
+	for i, value := range values {
+		if i == 0 {
+			deltas[i] = value
+			continue
+		}
+
+		if value < values[i-1] {
+			return []byte{}, newError(ErrNotSorted, i, value, -1, "value %d at index %d is lower than the previous value %d", value, i, values[i-1])
+		}
+		deltas[i] = value - values[i-1]
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	data := buf[:binary.PutUvarint(buf, uint64(len(values)))]
+
+	return appendEncode64(data, deltaRecordFields(7, fieldWidth, len(values)), deltas)
+}
+
+// Decode a sequence encoded with EncodeDeltas using the same field width.
+func DecodeDeltas(fieldWidth uint8, data []byte) ([]uint32, error) {
+	fields, offset, err := decodeDeltaHeader(6, fieldWidth, data)
+	if err != nil {
+		return []uint32{}, err
+	}
+
+	values := make([]uint32, len(fields))
+	if _, _, err := decodeInto(values, fields, prefixBitsOf(fields), data[offset:]); err != nil {
+		return []uint32{}, err
+	}
+
+	for i := 1; i < len(values); i++ {
+		if values[i] > ^uint32(0)-values[i-1] {
+			return []uint32{}, newError(ErrValueTooLarge, i, uint64(values[i]), -1, "delta %d at index %d overflows previous value %d", values[i], i, values[i-1])
+		}
+		values[i] += values[i-1]
+	}
+
+	return values, nil
+}
+
+// Decode a sequence encoded with EncodeDeltas64 using the same field width.
+func DecodeDeltas64(fieldWidth uint8, data []byte) ([]uint64, error) {
+	fields, offset, err := decodeDeltaHeader(7, fieldWidth, data)
+	if err != nil {
+		return []uint64{}, err
+	}
+
+	values := make([]uint64, len(fields))
+	if _, _, err := decodeInto64(values, fields, prefixBitsOf(fields), data[offset:]); err != nil {
+		return []uint64{}, err
+	}
+
+	for i := 1; i < len(values); i++ {
+		if values[i] > ^uint64(0)-values[i-1] {
+			return []uint64{}, newError(ErrValueTooLarge, i, values[i], -1, "delta %d at index %d overflows previous value %d", values[i], i, values[i-1])
+		}
+		values[i] += values[i-1]
+	}
+
+	return values, nil
+}
+
+// Return an error unless fieldWidth is from 1 to maxWidth, the width of the
+// first value's field, which is enough to describe any value.
+func validateDeltaWidth(fieldWidth uint8, maxWidth uint8) error {
+	if fieldWidth == 0 || fieldWidth > maxWidth {
+		return newError(ErrInvalidWidth, -1, uint64(fieldWidth), -1, "invalid delta field width %d, expected 1 to %d", fieldWidth, maxWidth)
+	}
+
+	return nil
+}
+
+// Return count fields of the given width.
+func deltaFields(fieldWidth uint8, count int) []uint8 {
+	fields := make([]uint8, count)
+	for i := range fields {
+		fields[i] = fieldWidth
+	}
+
+	return fields
+}
+
+// Return the fields of a record of count values whose first value has a
+// field of firstWidth and whose deltas have fields of fieldWidth.
+func deltaRecordFields(firstWidth uint8, fieldWidth uint8, count int) []uint8 {
+	fields := deltaFields(fieldWidth, count)
+	if count > 0 {
+		fields[0] = firstWidth
+	}
+
+	return fields
+}
+
+// Read the value count at the start of data, returning the fields of the
+// record that follows and its offset.
+func decodeDeltaHeader(firstWidth uint8, fieldWidth uint8, data []byte) ([]uint8, int, error) {
+	if err := validateDeltaWidth(fieldWidth, firstWidth); err != nil {
+		return nil, 0, err
+	}
+
+	if len(data) == 0 {
+		return nil, 0, ErrEmpty
+	}
+
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, 0, newError(ErrTruncated, -1, 0, 0, "invalid value count")
+	}
+
+	// Every value takes up at least its width prefix, so a count larger than
+	// that allows cannot be right and must not be allocated for.
+	remaining := uint64(len(data)-n) * 8
+	if count > 0 && (remaining < uint64(firstWidth) || count-1 > (remaining-uint64(firstWidth))/uint64(fieldWidth)) {
+		return nil, 0, newError(ErrTruncated, -1, count, n*8, "ran out of data before end of width prefixes, expected %d values, got %d bits of data", count, remaining)
+	}
+
+	return deltaRecordFields(firstWidth, fieldWidth, int(count)), n, nil
+}
@@ -0,0 +1,176 @@
+package govarint
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type deltasTestCase struct {
+	fieldWidth uint8
+	values     []uint32
+	result     []byte
+}
+
+var (
+	deltasTests = []deltasTestCase{
+		{3, []uint32{}, []byte{0x00}},
+		{3, []uint32{0}, []byte{0x01, 0x00}},
+		{3, []uint32{5, 5, 6}, []byte{0x03, 0x0c, 0x14}},
+		{5, []uint32{1000000, 1000001, 1000003}, []byte{0x03, 0x50, 0x22, 0xe8, 0x48, 0x00}},
+	}
+)
+
+func TestEncodeDeltas(t *testing.T) {
+	for _, tc := range deltasTests {
+		result, err := EncodeDeltas(tc.fieldWidth, tc.values)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+
+		values, err := DecodeDeltas(tc.fieldWidth, result)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("Expected %v, got %v for %v", tc.values, values, tc)
+		}
+	}
+}
+
+// Large IDs with small gaps only need a narrow width for the deltas, since
+// the first value has a field of its own.
+func TestDeltasLargeIDs(t *testing.T) {
+	values := []uint32{1000000000, 1000000002, 1000000003, 1000000009}
+	values64 := []uint64{1000000000000, 1000000000002, 1000000000003, 1000000000009}
+
+	data, err := EncodeDeltas(3, values)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+	}
+
+	result, err := DecodeDeltas(3, data)
+	if err != nil || !reflect.DeepEqual(result, values) {
+		t.Errorf("Expected %v, got %v, %v", values, result, err)
+	}
+
+	plain, _ := Encode(deltaFields(6, len(values)), values)
+	if len(data) >= 1+len(plain) {
+		t.Errorf("Expected less than %d bytes, got %d", 1+len(plain), len(data))
+	}
+
+	data, err = EncodeDeltas64(3, values64)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\" for %v", err, values64)
+	}
+
+	result64, err := DecodeDeltas64(3, data)
+	if err != nil || !reflect.DeepEqual(result64, values64) {
+		t.Errorf("Expected %v, got %v, %v", values64, result64, err)
+	}
+
+	if _, err := EncodeDeltas(3, []uint32{0, 4294967295}); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge for a wide delta, got %v", err)
+	}
+}
+
+func TestDeltasRoundTrip(t *testing.T) {
+	plainSize := 0
+	deltasSize := 0
+
+	for testCount := 0; testCount < 1000; testCount++ {
+		values := make([]uint64, rand.Int31n(1000))
+		for i := range values {
+			values[i] = uint64(rand.Int63n(100000000))
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+		data, err := EncodeDeltas64(6, values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		result, err := DecodeDeltas64(6, data)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		if !reflect.DeepEqual(result, values) {
+			t.Fatalf("Expected %v, got %v", values, result)
+		}
+
+		plain, _ := Encode64(deltaFields(6, len(values)), values)
+		plainSize += len(plain)
+		deltasSize += len(data)
+	}
+
+	if deltasSize >= plainSize {
+		t.Errorf("Expected deltas to take up less than %d bytes, got %d", plainSize, deltasSize)
+	}
+}
+
+func TestDeltasErrors(t *testing.T) {
+	if _, err := EncodeDeltas(3, []uint32{2, 1}); !errors.Is(err, ErrNotSorted) {
+		t.Errorf("Expected ErrNotSorted, got %v", err)
+	}
+
+	if _, err := EncodeDeltas64(3, []uint64{1, 1 << 8}); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	}
+
+	if _, err := EncodeDeltas(Signed(3), []uint32{}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+
+	// Widths able to describe more bits than the values hold.
+	for _, fieldWidth := range []uint8{7, 33, 40, 64} {
+		if _, err := EncodeDeltas(fieldWidth, []uint32{1, 5, 1000}); !errors.Is(err, ErrInvalidWidth) {
+			t.Errorf("Expected ErrInvalidWidth for width %d, got %v", fieldWidth, err)
+		}
+
+		if _, err := DecodeDeltas(fieldWidth, []byte{0x01, 0x04}); !errors.Is(err, ErrInvalidWidth) {
+			t.Errorf("Expected ErrInvalidWidth for width %d, got %v", fieldWidth, err)
+		}
+
+		if fieldWidth == 7 {
+			continue
+		}
+
+		if _, err := EncodeDeltas64(fieldWidth, []uint64{1, 5, 1000}); !errors.Is(err, ErrInvalidWidth) {
+			t.Errorf("Expected ErrInvalidWidth for 64-bit width %d, got %v", fieldWidth, err)
+		}
+
+		if _, err := DecodeDeltas64(fieldWidth, []byte{0x01, 0x02}); !errors.Is(err, ErrInvalidWidth) {
+			t.Errorf("Expected ErrInvalidWidth for 64-bit width %d, got %v", fieldWidth, err)
+		}
+	}
+
+	if _, err := DecodeDeltas(3, []byte{}); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+
+	if _, err := DecodeDeltas(3, []byte{0x80}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	// A count far beyond what the data could hold.
+	if _, err := DecodeDeltas(3, []byte{0xff, 0xff, 0xff, 0xff, 0x0f, 0x00}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	// Two values of 32 bits whose sum does not fit in 32 bits.
+	data, _ := Encode([]uint8{6, 6}, []uint32{1 << 31, 1 << 31})
+	if _, err := DecodeDeltas(6, append([]byte{2}, data...)); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package govarint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Write an annotated map of the bits of an encoded record to w, one line per
+// width prefix and value giving its bit offset, its bits and what they
+// describe. The leading bit of each value, which is implied by its width and
+// not stored, is shown in brackets. Padding and trailing bytes after the
+// record are shown last.
+//
+// Dump is meant for debugging and does not reject padding or trailing data.
+// If the record is truncated or holds an invalid width, the bits read so far
+// are written before returning the error.
+func Dump(w io.Writer, fields []uint8, data []byte) error {
+	if err := validateFields(fields); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	err := dump(tw, fields, data)
+	if flushErr := tw.Flush(); err == nil {
+		err = flushErr
+	}
+
+	return err
+}
+
+// Return the annotated bit map written by Dump as a string.
+func Explain(fields []uint8, data []byte) (string, error) {
+	buf := &bytes.Buffer{}
+	err := Dump(buf, fields, data)
+	return buf.String(), err
+}
+
+func dump(w io.Writer, fields []uint8, data []byte) error {
+	totalBits := len(data) * 8
+	prefixBits := prefixBitsOf(fields)
+
+	widths := make([]uint, len(fields))
+	prefixPos := 0
+	for i, fieldWidth := range fields {
+		fieldWidth &^= SignedField
+		if prefixPos+int(fieldWidth) > totalBits {
+			return newError(ErrTruncated, -1, 0, totalBits, "ran out of data before end of width prefixes, expected %d bits of data, got %d", prefixBits, totalBits)
+		}
+
+		widths[i] = uint(getBits(data, prefixPos, uint(fieldWidth)))
+		dumpLine(w, prefixPos, bitString(data, prefixPos, uint(fieldWidth)), "field %d width prefix %d", i, widths[i])
+
+		if widths[i] > 64 {
+			return newError(ErrInvalidWidth, i, uint64(widths[i]), prefixPos, "invalid value width %d for 64-bit values", widths[i])
+		}
+
+		prefixPos += int(fieldWidth)
+	}
+
+	valuePos := prefixBits
+	for i, width := range widths {
+		if width == 0 {
+			dumpLine(w, valuePos, "-", "field %d value 0", i)
+			continue
+		}
+
+		if valuePos+int(width)-1 > totalBits {
+			return newError(ErrTruncated, i, uint64(width), valuePos, "ran out of data before end of value, expected additional %d bits of data", valuePos+int(width)-1-totalBits)
+		}
+
+		value := uint64(1)<<(width-1) | getBits(data, valuePos, width-1)
+		bits := "[1]" + bitString(data, valuePos, width-1)
+
+		if fields[i]&SignedField != 0 {
+			dumpLine(w, valuePos, bits, "field %d value %d, zigzag %d", i, int64(zigzagDecode64(value)), value)
+		} else {
+			dumpLine(w, valuePos, bits, "field %d value %d", i, value)
+		}
+
+		valuePos += int(width) - 1
+	}
+
+	size := (valuePos + 7) / 8
+	if padding := size*8 - valuePos; padding > 0 {
+		if getBits(data, valuePos, uint(padding)) != 0 {
+			dumpLine(w, valuePos, bitString(data, valuePos, uint(padding)), "padding, not zero")
+		} else {
+			dumpLine(w, valuePos, bitString(data, valuePos, uint(padding)), "padding")
+		}
+	}
+
+	if len(data) > size {
+		dumpLine(w, size*8, fmt.Sprintf("0x%x", data[size:]), "trailing data, %d bytes", len(data)-size)
+	}
+
+	return nil
+}
+
+func dumpLine(w io.Writer, bitOffset int, bits string, format string, args ...interface{}) {
+	fmt.Fprintf(w, "%4d  %s\t"+format+"\n", append([]interface{}{bitOffset, bits}, args...)...)
+}
+
+// Return the width bits at the given bit offset of data as a string of 0s
+// and 1s.
+func bitString(data []byte, bitOffset int, width uint) string {
+	if width == 0 {
+		return ""
+	}
+
+	s := strconv.FormatUint(getBits(data, bitOffset, width), 2)
+	return strings.Repeat("0", int(width)-len(s)) + s
+}
@@ -0,0 +1,91 @@
+package govarint
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type explainTestCase struct {
+	fields []uint8
+	data   []byte
+	result string
+}
+
+var (
+	explainTests = []explainTestCase{
+		{[]uint8{1}, []byte{0x00}, "" +
+			"   0  0        field 0 width prefix 0\n" +
+			"   1  -        field 0 value 0\n" +
+			"   1  0000000  padding\n"},
+		{[]uint8{3, Signed(3)}, []byte{0x2d, 0xab}, "" +
+			"   0  001    field 0 width prefix 1\n" +
+			"   3  011    field 1 width prefix 3\n" +
+			"   6  [1]    field 0 value 1\n" +
+			"   6  [1]01  field 1 value -3, zigzag 5\n" +
+			"   8  0xab   trailing data, 1 bytes\n"},
+		{[]uint8{2, 3}, []byte{0x4c}, "" +
+			"   0  01   field 0 width prefix 1\n" +
+			"   2  001  field 1 width prefix 1\n" +
+			"   5  [1]  field 0 value 1\n" +
+			"   5  [1]  field 1 value 1\n" +
+			"   5  100  padding, not zero\n"},
+	}
+)
+
+func TestExplain(t *testing.T) {
+	for _, tc := range explainTests {
+		result, err := Explain(tc.fields, tc.data)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if result != tc.result {
+			t.Errorf("Expected:\n%s\ngot:\n%s\nfor %v", tc.result, result, tc)
+		}
+	}
+}
+
+// Every field of a round-tripped record shows up with its decoded value.
+func TestExplainRoundTrip(t *testing.T) {
+	fields := []uint8{3, 3, 6, 3, 6}
+	values := []uint32{1, 5, 1128411, 2, 123456789}
+	data, _ := Encode(fields, values)
+
+	result, err := Explain(fields, data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, expected := range []string{
+		"field 0 value 1\n",
+		"field 1 value 5\n",
+		"field 2 value 1128411\n",
+		"field 3 value 2\n",
+		"field 4 value 123456789\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, result)
+		}
+	}
+}
+
+func TestExplainTruncated(t *testing.T) {
+	result, err := Explain([]uint8{3, 3, 6}, []byte{0x2d, 0x54})
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if !strings.Contains(result, "field 2 width prefix 21\n") {
+		t.Errorf("Expected the width prefixes read before the error, got:\n%s", result)
+	}
+
+	if _, err := Explain([]uint8{3, 3, 6}, []byte{0x2d}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if _, err := Explain([]uint8{7}, []byte{0xff}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+}
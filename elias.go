@@ -0,0 +1,189 @@
+package govarint
+
+import (
+	"fmt"
+)
+
+// A WidthCode selects how EncodeElias codes the width of each value.
+type WidthCode int
+
+const (
+	// Code the width w as the Elias gamma code of w+1: as many zero bits as
+	// w+1 has bits after its leading one, then w+1 itself. Small widths
+	// take the fewest bits.
+	EliasGamma WidthCode = iota
+	// Code the width w as the Elias delta code of w+1: the gamma code of
+	// the number of bits of w+1, then w+1 without its leading one. Wide
+	// values take fewer bits than with EliasGamma.
+	EliasDelta
+)
+
+// Encode a list of values without a schema. Rather than reading widths from
+// fixed-width prefixes, the width of each value is coded with the given
+// Elias code and followed directly by the value, leaving out its implied
+// leading bit like Encode does. Every width code contains a 1 bit, so the
+// zero padding after the last value can not be mistaken for another value
+// and DecodeElias needs no count.
+func EncodeElias(code WidthCode, values []uint32) ([]byte, error) {
+	values64 := make([]uint64, len(values))
+	for i, value := range values {
+		values64[i] = uint64(value)
+	}
+
+	return encodeElias(code, values64)
+}
+
+// Encode a list of 64-bit values without a schema, see EncodeElias.
+func EncodeElias64(code WidthCode, values []uint64) ([]byte, error) {
+	return encodeElias(code, values)
+}
+
+// Decode a list of values encoded with EncodeElias using the same code.
+func DecodeElias(code WidthCode, data []byte) ([]uint32, error) {
+	values64, err := decodeElias(code, data, 32)
+	if err != nil {
+		return []uint32{}, err
+	}
+
+	values := make([]uint32, len(values64))
+	for i, value := range values64 {
+		values[i] = uint32(value)
+	}
+
+	return values, nil
+}
+
+// Decode a list of 64-bit values encoded with EncodeElias64.
+func DecodeElias64(code WidthCode, data []byte) ([]uint64, error) {
+	values, err := decodeElias(code, data, 64)
+	if err != nil {
+		return []uint64{}, err
+	}
+
+	return values, nil
+}
+
+func encodeElias(code WidthCode, values []uint64) ([]byte, error) {
+	if code != EliasGamma && code != EliasDelta {
+		return []byte{}, fmt.Errorf("unknown width code %d", code)
+	}
+
+	w := NewBitWriter(make([]byte, 0, len(values)*2))
+	for _, value := range values {
+		valueWidth := uint8(64 - countLeadingZeros64(value))
+
+		if code == EliasGamma {
+			writeGamma(w, uint64(valueWidth)+1)
+		} else {
+			writeDelta(w, uint64(valueWidth)+1)
+		}
+
+		if valueWidth > 1 {
+			w.WriteBits(value, valueWidth-1)
+		}
+	}
+
+	return w.Bytes(), nil
+}
+
+// Write the Elias gamma code of n, which must be at least 1.
+func writeGamma(w *BitWriter, n uint64) {
+	bits := uint8(64 - countLeadingZeros64(n))
+	w.WriteBits(0, bits-1)
+	w.WriteBits(n, bits)
+}
+
+// Write the Elias delta code of n, which must be at least 1.
+func writeDelta(w *BitWriter, n uint64) {
+	bits := uint8(64 - countLeadingZeros64(n))
+	writeGamma(w, uint64(bits))
+	w.WriteBits(n, bits-1)
+}
+
+// Decode values of at most valueBits bits until only zero padding is left.
+func decodeElias(code WidthCode, data []byte, valueBits uint64) ([]uint64, error) {
+	if code != EliasGamma && code != EliasDelta {
+		return nil, fmt.Errorf("unknown width code %d", code)
+	}
+
+	values := []uint64{}
+	r := NewBitReader(data)
+	for !onlyPadding(data, r.Offset()) {
+		bitOffset := r.Offset()
+
+		var n uint64
+		var err error
+		if code == EliasGamma {
+			n, err = readGamma(r, valueBits+1)
+		} else {
+			n, err = readDelta(r, valueBits+1)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		width := n - 1
+		if width > valueBits {
+			return nil, newError(ErrInvalidWidth, len(values), width, bitOffset, "invalid value width %d for %d-bit values", width, valueBits)
+		}
+
+		value := uint64(0)
+		if width > 0 {
+			bits, err := r.ReadBits(uint8(width - 1))
+			if err != nil {
+				return nil, newError(ErrTruncated, len(values), width, r.Offset(), "ran out of data before end of value, expected additional %d bits of data", int(width)-1-r.Remaining())
+			}
+			value = 1<<(width-1) | bits
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// Read an Elias gamma code, returning an error if it is larger than max.
+func readGamma(r *BitReader, max uint64) (uint64, error) {
+	bitOffset := r.Offset()
+	maxZeros := 64 - countLeadingZeros64(max) - 1
+
+	zeros := 0
+	for {
+		bit, err := r.ReadBits(1)
+		if err != nil {
+			return 0, newError(ErrTruncated, -1, 0, bitOffset, "ran out of data before end of width code")
+		}
+		if bit == 1 {
+			break
+		}
+
+		zeros++
+		if zeros > maxZeros {
+			return 0, newError(ErrInvalidWidth, -1, 0, bitOffset, "width code longer than %d bits", 2*maxZeros+1)
+		}
+	}
+
+	bits, err := r.ReadBits(uint8(zeros))
+	if err != nil {
+		return 0, newError(ErrTruncated, -1, 0, bitOffset, "ran out of data before end of width code")
+	}
+
+	return 1<<uint(zeros) | bits, nil
+}
+
+// Read an Elias delta code, returning an error if it is larger than max.
+func readDelta(r *BitReader, max uint64) (uint64, error) {
+	bitOffset := r.Offset()
+
+	bits, err := readGamma(r, uint64(64-countLeadingZeros64(max)))
+	if err != nil {
+		return 0, err
+	}
+
+	low, err := r.ReadBits(uint8(bits - 1))
+	if err != nil {
+		return 0, newError(ErrTruncated, -1, 0, bitOffset, "ran out of data before end of width code")
+	}
+
+	return 1<<(bits-1) | low, nil
+}
@@ -0,0 +1,119 @@
+package govarint
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type eliasTestCase struct {
+	code   WidthCode
+	values []uint32
+	result []byte
+}
+
+var (
+	eliasTests = []eliasTestCase{
+		{EliasGamma, []uint32{}, []byte{}},
+		{EliasGamma, []uint32{0}, []byte{0x80}},
+		{EliasGamma, []uint32{0, 0, 0, 0, 0, 0, 0, 0}, []byte{0xff}},
+		{EliasGamma, []uint32{1}, []byte{0x40}},
+		{EliasGamma, []uint32{5}, []byte{0x22}},
+		{EliasGamma, []uint32{5, 0}, []byte{0x23}},
+		{EliasDelta, []uint32{0}, []byte{0x80}},
+		{EliasDelta, []uint32{1}, []byte{0x40}},
+		{EliasDelta, []uint32{5}, []byte{0x62}},
+		{EliasDelta, []uint32{0xffffffff}, []byte{0x30, 0x7f, 0xff, 0xff, 0xff, 0x80}},
+	}
+)
+
+func TestEncodeElias(t *testing.T) {
+	for _, tc := range eliasTests {
+		result, err := EncodeElias(tc.code, tc.values)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+
+		values, err := DecodeElias(tc.code, result)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("Expected %v, got %v for %v", tc.values, values, tc)
+		}
+	}
+}
+
+func TestEliasRoundTrip(t *testing.T) {
+	for _, code := range []WidthCode{EliasGamma, EliasDelta} {
+		for testCount := 0; testCount < 10000; testCount++ {
+			values := make([]uint64, rand.Int31n(30))
+			for i := range values {
+				values[i] = rand.Uint64() >> uint(rand.Int31n(65)) >> 1
+			}
+
+			data, err := EncodeElias64(code, values)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+			}
+
+			result, err := DecodeElias64(code, data)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+			}
+
+			if !reflect.DeepEqual(result, values) {
+				t.Fatalf("Expected %v, got %v for code %d", values, result, code)
+			}
+		}
+	}
+}
+
+// Mostly small values take up less space than with fixed-width prefixes.
+func TestEliasSize(t *testing.T) {
+	values := make([]uint32, 1000)
+	for i := range values {
+		values[i] = uint32(rand.Int31n(16))
+	}
+
+	data, _ := EncodeElias(EliasGamma, values)
+	record, _ := Encode(deltaFields(6, len(values)), values)
+	if len(data) >= len(record) {
+		t.Errorf("Expected less than %d bytes, got %d", len(record), len(data))
+	}
+}
+
+func TestDecodeEliasErrors(t *testing.T) {
+	if _, err := EncodeElias(WidthCode(2), []uint32{}); err == nil {
+		t.Errorf("Expected error for unknown width code")
+	}
+
+	// Gamma code of 33 followed by too few value bits.
+	if _, err := DecodeElias(EliasGamma, []byte{0x04, 0x20, 0x00}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	// Gamma code of 34, a width too large for 32-bit values.
+	if _, err := DecodeElias(EliasGamma, []byte{0x04, 0x40, 0x00, 0x00, 0x00, 0x00}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+
+	// Too many leading zeros for any valid width.
+	if _, err := DecodeElias(EliasGamma, []byte{0x00, 0xff}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+
+	// A whole byte of zeros is not padding.
+	if _, err := DecodeElias(EliasDelta, []byte{0x80, 0x00}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package govarint
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned when encoding or decoding fails. Apart from ErrEmpty they
+// are returned wrapped in an *Error giving details of where the failure
+// occurred, so compare against them with errors.Is.
+var (
+	// The record is empty.
+	ErrEmpty = errors.New("empty input")
+	// A field width is zero or too large, or a width prefix describes more
+	// bits than the value type holds.
+	ErrInvalidWidth = errors.New("invalid value width")
+	// The record ends before all width prefixes and values have been read.
+	ErrTruncated = errors.New("ran out of data")
+	// The bits after the last value are not all zero.
+	ErrNonZeroPadding = errors.New("non-zero padding bits")
+	// The data continues after the end of the record.
+	ErrTrailingData = errors.New("trailing data")
+	// A value does not fit in its field, or a decoded value does not fit in
+	// its destination.
+	ErrValueTooLarge = errors.New("value too large")
+	// The number of values does not match the number of fields.
+	ErrFieldCountMismatch = errors.New("mismatched field and value count")
+	// A value passed to EncodeDeltas is lower than the one before it.
+	ErrNotSorted = errors.New("values not sorted")
+)
+
+// An Error describes which field of a record could not be encoded or
+// decoded. Use errors.As to retrieve it.
+type Error struct {
+	// One of the Err values above.
+	Err error
+	// Index of the field, or -1 if the error does not concern one field.
+	Field int
+	// The value that could not be encoded, or the width prefix that was
+	// being decoded.
+	Value uint64
+	// Offset in bits from the start of the record where decoding failed, or
+	// -1 for encoding errors.
+	BitOffset int
+
+	msg string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func newError(err error, field int, value uint64, bitOffset int, format string, args ...interface{}) error {
+	return &Error{
+		Err:       err,
+		Field:     field,
+		Value:     value,
+		BitOffset: bitOffset,
+		msg:       fmt.Sprintf(format, args...),
+	}
+}
+
+func countMismatchError(fieldCount int, valueCount int) error {
+	return newError(ErrFieldCountMismatch, -1, 0, -1, "mismatched field and value count, got %d fields and %d values", fieldCount, valueCount)
+}
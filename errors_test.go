@@ -0,0 +1,118 @@
+package govarint
+
+import (
+	"errors"
+	"testing"
+)
+
+type errorTestCase struct {
+	name      string
+	run       func() error
+	err       error
+	field     int
+	value     uint64
+	bitOffset int
+}
+
+var (
+	errorTests = []errorTestCase{
+		{"Encode value too large", func() error {
+			_, err := Encode([]uint8{3, 2}, []uint32{1, 8})
+			return err
+		}, ErrValueTooLarge, 1, 8, -1},
+		{"Encode64 value too large", func() error {
+			_, err := Encode64([]uint8{6}, []uint64{1 << 63})
+			return err
+		}, ErrValueTooLarge, 0, 1 << 63, -1},
+		{"AppendEncode value too large", func() error {
+			_, err := AppendEncode(nil, []uint8{1, 1, 2}, []uint32{0, 1, 8})
+			return err
+		}, ErrValueTooLarge, 2, 8, -1},
+		{"Packer value too large", func() error {
+			var p Packer
+			p.PutUint(3, 1)
+			return p.PutInt(2, -8)
+		}, ErrValueTooLarge, 1, uint64(1<<64 - 8), -1},
+
+		{"Encode count mismatch", func() error {
+			_, err := Encode([]uint8{3, 2}, []uint32{1})
+			return err
+		}, ErrFieldCountMismatch, -1, 0, -1},
+		{"Schema count mismatch", func() error {
+			s, _ := NewSchema(3, 2)
+			_, err := s.Encode([]uint32{1, 2, 3})
+			return err
+		}, ErrFieldCountMismatch, -1, 0, -1},
+
+		{"Encode zero width", func() error {
+			_, err := Encode([]uint8{3, 2, 0}, []uint32{1, 2, 3})
+			return err
+		}, ErrInvalidWidth, 2, 0, -1},
+		{"Schema too wide", func() error {
+			_, err := NewSchema(3, 7)
+			return err
+		}, ErrInvalidWidth, 1, 7, -1},
+
+		// The second width prefix starts after three bits and claims 63 bits.
+		{"Decode invalid width", func() error {
+			_, err := Decode([]uint8{3, 6}, []byte{0x1f, 0x80, 0, 0, 0, 0, 0, 0, 0})
+			return err
+		}, ErrInvalidWidth, 1, 63, 3},
+		{"DecodeStrict invalid width", func() error {
+			_, err := DecodeStrict([]uint8{3, 6}, []byte{0x1f, 0x80, 0, 0, 0, 0, 0, 0, 0})
+			return err
+		}, ErrInvalidWidth, 1, 63, 3},
+		{"Unpacker invalid width", func() error {
+			var u Unpacker
+			u.Reset([]byte{0x1f, 0xff, 0, 0, 0, 0, 0, 0, 0, 0}, 10)
+			u.Uint(3, 64)
+			_, err := u.Uint(7, 64)
+			return err
+		}, ErrInvalidWidth, 1, 127, 3},
+
+		// The second value of {1, 12345} needs 14 bits from bit 9 onwards.
+		{"DecodeStrict truncated", func() error {
+			_, err := DecodeStrict([]uint8{4, 5}, []byte{0x17, 0x40})
+			return err
+		}, ErrTruncated, 1, 14, 9},
+		{"Decode truncated", func() error {
+			_, err := Decode([]uint8{4, 5}, []byte{0x17, 0x40})
+			return err
+		}, ErrTruncated, 1, 14, 9},
+
+		{"DecodeStrict padding", func() error {
+			_, err := DecodeStrict([]uint8{4, 5}, []byte{0x17, 0x40, 0xe5})
+			return err
+		}, ErrNonZeroPadding, -1, 0, 22},
+		{"DecodeStrict trailing data", func() error {
+			_, err := DecodeStrict([]uint8{4, 5}, []byte{0x17, 0x40, 0xe4, 0})
+			return err
+		}, ErrTrailingData, -1, 0, 24},
+	}
+)
+
+func TestErrors(t *testing.T) {
+	for _, tc := range errorTests {
+		err := tc.run()
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected error \"%s\", got: %v", tc.name, tc.err, err)
+			continue
+		}
+
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("%s: expected *Error, got %T", tc.name, err)
+			continue
+		}
+
+		if e.Field != tc.field {
+			t.Errorf("%s: expected field %d, got %d", tc.name, tc.field, e.Field)
+		}
+		if e.Value != tc.value {
+			t.Errorf("%s: expected value %d, got %d", tc.name, tc.value, e.Value)
+		}
+		if e.BitOffset != tc.bitOffset {
+			t.Errorf("%s: expected bit offset %d, got %d", tc.name, tc.bitOffset, e.BitOffset)
+		}
+	}
+}
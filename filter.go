@@ -0,0 +1,176 @@
+package govarint
+
+import (
+	"fmt"
+)
+
+// A Predicate tests whether the value of a field lies between Min and Max,
+// inclusive. Values of signed fields are compared as signed integers, with
+// Min and Max given as their two's complement like the values passed to
+// Encode64.
+type Predicate struct {
+	Field int
+	Min   uint64
+	Max   uint64
+}
+
+// Return a predicate matching records whose field has the given value.
+func Equal(field int, value uint64) Predicate {
+	return Predicate{Field: field, Min: value, Max: value}
+}
+
+// Return a predicate matching records whose field lies between min and max,
+// inclusive.
+func Between(field int, min uint64, max uint64) Predicate {
+	return Predicate{Field: field, Min: min, Max: max}
+}
+
+// A Filter selects the records matching all of its predicates from
+// encoded data, without decoding the fields it does not test.
+//
+// The width prefix of an unsigned field bounds its value, so most
+// records are accepted or rejected from their prefixes alone and the value
+// bits are only read when the bounds straddle a predicate's range.
+type Filter struct {
+	fields     []uint8
+	prefixBits int
+	// Predicates on each field.
+	predicates [][]Predicate
+}
+
+// Create a filter for records encoded with the given fields.
+func NewFilter(fields []uint8, predicates ...Predicate) (*Filter, error) {
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	return newFilter(fields, prefixBitsOf(fields), predicates)
+}
+
+// Create a filter for records encoded with the schema.
+func (s *Schema) Filter(predicates ...Predicate) (*Filter, error) {
+	return newFilter(s.fields, s.prefixBits, predicates)
+}
+
+func newFilter(fields []uint8, prefixBits int, predicates []Predicate) (*Filter, error) {
+	// Records without fields take up no bytes, so Offsets could not step
+	// past them.
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("filter requires at least one field")
+	}
+
+	f := &Filter{
+		fields:     fields,
+		prefixBits: prefixBits,
+		predicates: make([][]Predicate, len(fields)),
+	}
+
+	for _, p := range predicates {
+		if p.Field < 0 || p.Field >= len(fields) {
+			return nil, fmt.Errorf("predicate field %d out of range for %d fields", p.Field, len(fields))
+		}
+
+		f.predicates[p.Field] = append(f.predicates[p.Field], p)
+	}
+
+	return f, nil
+}
+
+// Return whether the record at the start of data matches the filter, along
+// with the size of the record in bytes.
+func (f *Filter) Match(data []byte) (bool, int, error) {
+	totalBits := len(data) * 8
+	if f.prefixBits > totalBits {
+		return false, 0, newError(ErrTruncated, -1, 0, totalBits, "ran out of data before end of width prefixes, expected %d bits of data, got %d", f.prefixBits, totalBits)
+	}
+
+	matched := true
+	prefixPos := 0
+	valuePos := f.prefixBits
+	for i, fieldWidth := range f.fields {
+		width := uint(getBits(data, prefixPos, uint(fieldWidth&^SignedField)))
+		if width > 64 {
+			return false, 0, newError(ErrInvalidWidth, i, uint64(width), prefixPos, "invalid value width %d for 64-bit values", width)
+		}
+		prefixPos += int(fieldWidth &^ SignedField)
+
+		if matched && len(f.predicates[i]) > 0 {
+			if width > 0 && valuePos+int(width)-1 > totalBits {
+				return false, 0, newError(ErrTruncated, i, uint64(width), valuePos, "ran out of data before end of value, expected additional %d bits of data", valuePos+int(width)-1-totalBits)
+			}
+
+			matched = f.matchField(i, data, valuePos, width)
+		}
+
+		if width > 0 {
+			valuePos += int(width) - 1
+		}
+	}
+
+	if valuePos > totalBits {
+		return false, 0, newError(ErrTruncated, -1, 0, totalBits, "ran out of data before end of values, expected %d bits of data, got %d", valuePos, totalBits)
+	}
+
+	return matched, (valuePos + 7) / 8, nil
+}
+
+// Return the byte offsets of the records in data matching the filter. The
+// data must hold whole records one after another.
+func (f *Filter) Offsets(data []byte) ([]int, error) {
+	offsets := []int{}
+	for offset := 0; offset < len(data); {
+		matched, n, err := f.Match(data[offset:])
+		if err != nil {
+			return offsets, err
+		}
+
+		if matched {
+			offsets = append(offsets, offset)
+		}
+		offset += n
+	}
+
+	return offsets, nil
+}
+
+// Return whether field i, with the given value width and value bits
+// starting at valuePos, satisfies all predicates on it.
+func (f *Filter) matchField(i int, data []byte, valuePos int, width uint) bool {
+	signed := f.fields[i]&SignedField != 0
+
+	// Bounds of the values of this width.
+	low, high := uint64(0), uint64(0)
+	if width > 0 {
+		low = uint64(1) << (width - 1)
+		high = low | (low - 1)
+	}
+
+	value := uint64(0)
+	haveValue := width == 0
+	for _, p := range f.predicates[i] {
+		if !signed {
+			if high < p.Min || low > p.Max {
+				return false
+			}
+			if low >= p.Min && high <= p.Max {
+				continue
+			}
+		}
+
+		if !haveValue {
+			value = low | getBits(data, valuePos, width-1)
+			haveValue = true
+		}
+
+		if signed {
+			v := int64(zigzagDecode64(value))
+			if v < int64(p.Min) || v > int64(p.Max) {
+				return false
+			}
+		} else if value < p.Min || value > p.Max {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,140 @@
+package govarint
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type filterTestCase struct {
+	predicates []Predicate
+	offsets    []int
+}
+
+var (
+	// Records of fields {3, Signed(3)}: (0, 0), (1, -1), (5, 3), (7, -8).
+	filterFields = []uint8{3, Signed(3)}
+	filterData   = []byte{0x00, 0x24, 0x6d, 0x80, 0x73, 0xe0}
+
+	filterTests = []filterTestCase{
+		{[]Predicate{}, []int{0, 1, 2, 4}},
+		{[]Predicate{Equal(0, 5)}, []int{2}},
+		{[]Predicate{Equal(0, 6)}, []int{}},
+		{[]Predicate{Between(0, 1, 5)}, []int{1, 2}},
+		{[]Predicate{Between(0, 4, 100)}, []int{2, 4}},
+		{[]Predicate{Equal(1, 0)}, []int{0}},
+		{[]Predicate{Between(1, uint64(1<<64-8), 0)}, []int{0, 1, 4}},
+		{[]Predicate{Between(0, 1, 7), Between(1, uint64(1<<64-1), 100)}, []int{1, 2}},
+		{[]Predicate{Between(0, 1, 7), Equal(0, 1)}, []int{1}},
+	}
+)
+
+func TestFilterOffsets(t *testing.T) {
+	for _, tc := range filterTests {
+		f, err := NewFilter(filterFields, tc.predicates...)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		offsets, err := f.Offsets(filterData)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !reflect.DeepEqual(offsets, tc.offsets) {
+			t.Errorf("Expected offsets %v, got %v for %v", tc.offsets, offsets, tc)
+		}
+	}
+}
+
+// Filtering must select exactly the records a full decode would.
+func TestFilterRandom(t *testing.T) {
+	schema, err := NewSchema(3, 3, 6, Signed(3), 6)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	data := []byte{}
+	records := [][]uint64{}
+	offsets := []int{}
+	for i := 0; i < 10000; i++ {
+		values := []uint64{
+			uint64(rand.Int31n(8)),
+			uint64(rand.Int31n(8)),
+			uint64(rand.Int31n(1000)),
+			uint64(rand.Int63n(8) - 4),
+			uint64(rand.Int31n(100000000)),
+		}
+
+		encoded, err := schema.Encode64(values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		offsets = append(offsets, len(data))
+		data = append(data, encoded...)
+		records = append(records, values)
+	}
+
+	for testCount := 0; testCount < 100; testCount++ {
+		a, b := uint64(rand.Int31n(1100)), uint64(rand.Int31n(1100))
+		if a > b {
+			a, b = b, a
+		}
+		low := uint64(rand.Int63n(8) - 4)
+		first := uint64(rand.Int31n(8))
+
+		f, err := schema.Filter(Between(2, a, b), Between(3, low, 3), Equal(0, first))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expected := []int{}
+		for i, values := range records {
+			if values[2] >= a && values[2] <= b && int64(values[3]) >= int64(low) && values[0] == first {
+				expected = append(expected, offsets[i])
+			}
+		}
+
+		result, err := f.Offsets(data)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("Expected %d matches, got %d for %d to %d", len(expected), len(result), a, b)
+		}
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	if _, err := NewFilter(filterFields, Equal(2, 0)); err == nil {
+		t.Errorf("Expected error for predicate on field out of range")
+	}
+
+	if _, err := NewFilter([]uint8{0}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+
+	// Records without fields would never advance Offsets.
+	if _, err := NewFilter([]uint8{}); err == nil {
+		t.Errorf("Expected error for no fields")
+	}
+
+	f, _ := NewFilter(filterFields, Equal(0, 7))
+	offsets, err := f.Offsets(filterData[:len(filterData)-1])
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+	if !reflect.DeepEqual(offsets, []int{}) {
+		t.Errorf("Expected no offsets, got %v", offsets)
+	}
+
+	f, _ = NewFilter([]uint8{7}, Equal(0, 1))
+	if _, _, err := f.Match([]byte{0xff}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package govarint
+
+// Largest quotient EncodeRice writes in unary, so that a single outlier can
+// not blow up the output. Use EncodeExpGolomb for values with a long tail.
+const maxRiceQuotient = 1 << 12
+
+// Encode values with Golomb-Rice coding using parameter k: the quotient
+// value>>k is written in unary as that many 0 bits followed by a 1 bit, then
+// the k low bits of the value. This suits geometrically distributed values
+// such as small counts and time gaps, given a k close to the log2 of their
+// mean, see EstimateRiceParameter.
+//
+// The unary quotient of every value is ended by a 1 bit, which the zero
+// padding after the last value never holds, so DecodeRice needs no count.
+func EncodeRice(k uint8, values []uint32) ([]byte, error) {
+	if k > 31 {
+		return []byte{}, newError(ErrInvalidWidth, -1, uint64(k), -1, "invalid Rice parameter %d, expected at most 31", k)
+	}
+
+	var curByte uint8
+	var curIndex uint8
+	data := make([]byte, 0, len(values)*(int(k)+2)/8+1)
+
+	for i, value := range values {
+		quotient := value >> k
+		if quotient > maxRiceQuotient {
+			return []byte{}, newError(ErrValueTooLarge, i, uint64(value), -1, "value %d too large for Rice parameter %d", value, k)
+		}
+
+		addUnary(&data, quotient, &curByte, &curIndex)
+		addBitsToSlice(&data, value, k, &curByte, &curIndex, false)
+	}
+
+	// Add trailing bits.
+	if curIndex > 0 {
+		addBitsToSlice(&data, 0, 8-curIndex, &curByte, &curIndex, false)
+	}
+
+	return data, nil
+}
+
+// Decode values encoded with EncodeRice using the same parameter.
+func DecodeRice(k uint8, data []byte) ([]uint32, error) {
+	if k > 31 {
+		return []uint32{}, newError(ErrInvalidWidth, -1, uint64(k), -1, "invalid Rice parameter %d, expected at most 31", k)
+	}
+
+	values := []uint32{}
+	r := NewBitReader(data)
+	for !onlyPadding(data, r.Offset()) {
+		bitOffset := r.Offset()
+
+		quotient, err := readUnary(r, maxRiceQuotient, len(values), "quotient")
+		if err != nil {
+			return []uint32{}, err
+		}
+
+		if quotient > ^uint32(0)>>k {
+			return []uint32{}, newError(ErrValueTooLarge, len(values), uint64(quotient), bitOffset, "quotient %d too large for 32-bit values", quotient)
+		}
+
+		remainder, err := r.ReadBits(k)
+		if err != nil {
+			return []uint32{}, newError(ErrTruncated, len(values), 0, bitOffset, "ran out of data before end of remainder")
+		}
+
+		values = append(values, quotient<<k|uint32(remainder))
+	}
+
+	return values, nil
+}
+
+// Encode values with Exp-Golomb coding of order k: value+2^k is written
+// after as many 0 bits as it has bits beyond k+1. Unlike Rice coding, the
+// code grows with the log of the value, so large outliers stay cheap.
+func EncodeExpGolomb(k uint8, values []uint32) ([]byte, error) {
+	if k > 31 {
+		return []byte{}, newError(ErrInvalidWidth, -1, uint64(k), -1, "invalid Exp-Golomb order %d, expected at most 31", k)
+	}
+
+	var curByte uint8
+	var curIndex uint8
+	data := make([]byte, 0, len(values)*(2*int(k)+2)/8+1)
+
+	for _, value := range values {
+		shifted := uint64(value) + 1<<k
+		width := uint8(64 - countLeadingZeros64(shifted))
+
+		// The leading 1 bit of the shifted value ends the run of zeros.
+		addUnary(&data, uint32(width-k-1), &curByte, &curIndex)
+		addBitsToSlice64(&data, shifted, width-1, &curByte, &curIndex, false)
+	}
+
+	// Add trailing bits.
+	if curIndex > 0 {
+		addBitsToSlice(&data, 0, 8-curIndex, &curByte, &curIndex, false)
+	}
+
+	return data, nil
+}
+
+// Decode values encoded with EncodeExpGolomb using the same order.
+func DecodeExpGolomb(k uint8, data []byte) ([]uint32, error) {
+	if k > 31 {
+		return []uint32{}, newError(ErrInvalidWidth, -1, uint64(k), -1, "invalid Exp-Golomb order %d, expected at most 31", k)
+	}
+
+	values := []uint32{}
+	r := NewBitReader(data)
+	for !onlyPadding(data, r.Offset()) {
+		bitOffset := r.Offset()
+
+		// Values of 32 bits plus 2^k take up at most 33 bits.
+		zeros, err := readUnary(r, 32-uint32(k), len(values), "prefix")
+		if err != nil {
+			return []uint32{}, err
+		}
+
+		width := uint8(zeros) + k
+		low, err := r.ReadBits(width)
+		if err != nil {
+			return []uint32{}, newError(ErrTruncated, len(values), 0, bitOffset, "ran out of data before end of value")
+		}
+
+		value := (uint64(1)<<width | low) - 1<<k
+		if value>>32 != 0 {
+			return []uint32{}, newError(ErrValueTooLarge, len(values), value, bitOffset, "value %d too large for 32-bit values", value)
+		}
+
+		values = append(values, uint32(value))
+	}
+
+	return values, nil
+}
+
+// Return the Rice parameter giving the smallest encoding of the samples.
+// Parameters leaving a quotient too large for EncodeRice are skipped, so
+// the result can always be used to encode the samples themselves.
+func EstimateRiceParameter(samples []uint32) uint8 {
+	max := uint32(0)
+	for _, sample := range samples {
+		if sample > max {
+			max = sample
+		}
+	}
+
+	bestK := uint8(0)
+	bestBits := ^uint64(0)
+	for k := uint8(0); k < 32; k++ {
+		if max>>k > maxRiceQuotient {
+			continue
+		}
+
+		bits := uint64(0)
+		for _, sample := range samples {
+			bits += uint64(sample>>k) + 1 + uint64(k)
+		}
+
+		if bits < bestBits {
+			bestK = k
+			bestBits = bits
+		}
+	}
+
+	return bestK
+}
+
+// Add n 0 bits followed by a 1 bit.
+func addUnary(slice *[]byte, n uint32, curByte *uint8, curIndex *uint8) {
+	for ; n >= 32; n -= 32 {
+		addBitsToSlice(slice, 0, 32, curByte, curIndex, false)
+	}
+
+	addBitsToSlice(slice, 1, uint8(n)+1, curByte, curIndex, false)
+}
+
+// Read up to max 0 bits followed by a 1 bit, returning the number of 0 bits.
+// Errors name what the code is for and the index of its value.
+func readUnary(r *BitReader, max uint32, field int, what string) (uint32, error) {
+	bitOffset := r.Offset()
+
+	n := uint32(0)
+	for {
+		bit, err := r.ReadBits(1)
+		if err != nil {
+			return 0, newError(ErrTruncated, field, 0, bitOffset, "ran out of data before end of %s", what)
+		}
+		if bit == 1 {
+			return n, nil
+		}
+
+		n++
+		if n > max {
+			return 0, newError(ErrValueTooLarge, field, 0, bitOffset, "%s of more than %d zero bits", what, max)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package govarint
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type golombTestCase struct {
+	k      uint8
+	values []uint32
+	result []byte
+}
+
+var (
+	riceTests = []golombTestCase{
+		{0, []uint32{}, []byte{}},
+		{0, []uint32{0}, []byte{0x80}},
+		{0, []uint32{3}, []byte{0x10}},
+		{2, []uint32{0, 5, 11}, []byte{0x8a, 0x70}},
+		{31, []uint32{0xffffffff}, []byte{0x7f, 0xff, 0xff, 0xff, 0x80}},
+	}
+
+	expGolombTests = []golombTestCase{
+		{0, []uint32{}, []byte{}},
+		{0, []uint32{0}, []byte{0x80}},
+		{0, []uint32{1, 2, 3}, []byte{0x4c, 0x80}},
+		{2, []uint32{0, 5}, []byte{0x89}},
+		{0, []uint32{0xffffffff}, []byte{0x00, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00}},
+	}
+)
+
+func TestEncodeRice(t *testing.T) {
+	for _, tc := range riceTests {
+		result, err := EncodeRice(tc.k, tc.values)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+
+		values, err := DecodeRice(tc.k, result)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("Expected %v, got %v for %v", tc.values, values, tc)
+		}
+	}
+}
+
+func TestEncodeExpGolomb(t *testing.T) {
+	for _, tc := range expGolombTests {
+		result, err := EncodeExpGolomb(tc.k, tc.values)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+
+		values, err := DecodeExpGolomb(tc.k, result)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("Expected %v, got %v for %v", tc.values, values, tc)
+		}
+	}
+}
+
+// Return values roughly geometrically distributed around the given mean.
+func geometricValues(count int, mean float64) []uint32 {
+	values := make([]uint32, count)
+	for i := range values {
+		values[i] = uint32(rand.ExpFloat64() * mean)
+	}
+
+	return values
+}
+
+func TestGolombRoundTrip(t *testing.T) {
+	for testCount := 0; testCount < 10000; testCount++ {
+		values := geometricValues(int(rand.Int31n(30)), float64(uint(1)<<uint(rand.Int31n(20))))
+		k := EstimateRiceParameter(values)
+
+		data, err := EncodeRice(k, values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		result, err := DecodeRice(k, data)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		if !reflect.DeepEqual(result, values) {
+			t.Fatalf("Expected %v, got %v for Rice parameter %d", values, result, k)
+		}
+
+		for i := range values {
+			values[i] = uint32(rand.Int63() & ((1 << uint(rand.Int31n(33))) - 1))
+		}
+		k = uint8(rand.Int31n(32))
+
+		data, err = EncodeExpGolomb(k, values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		result, err = DecodeExpGolomb(k, data)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		if !reflect.DeepEqual(result, values) {
+			t.Fatalf("Expected %v, got %v for Exp-Golomb order %d", values, result, k)
+		}
+	}
+}
+
+func TestEstimateRiceParameter(t *testing.T) {
+	values := geometricValues(10000, 1000)
+	k := EstimateRiceParameter(values)
+
+	best, _ := EncodeRice(k, values)
+	for _, other := range []uint8{k - 1, k + 1} {
+		data, err := EncodeRice(other, values)
+		if err == nil && len(data) < len(best) {
+			t.Errorf("Expected Rice parameter %d to beat %d, got %d and %d bytes", k, other, len(best), len(data))
+		}
+	}
+
+	// Rice coding beats width prefixes for geometric values.
+	record, _ := Encode(deltaFields(5, len(values)), values)
+	if len(best) >= len(record) {
+		t.Errorf("Expected less than %d bytes, got %d", len(record), len(best))
+	}
+
+	if k := EstimateRiceParameter([]uint32{}); k != 0 {
+		t.Errorf("Expected 0 for no samples, got %d", k)
+	}
+}
+
+// A single outlier would be cheapest with a parameter leaving it a quotient
+// EncodeRice rejects.
+func TestEstimateRiceParameterOutlier(t *testing.T) {
+	values := make([]uint32, 10000)
+	values[len(values)-1] = 10000000
+
+	k := EstimateRiceParameter(values)
+	data, err := EncodeRice(k, values)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\" for Rice parameter %d", err, k)
+	}
+
+	result, err := DecodeRice(k, data)
+	if err != nil || !reflect.DeepEqual(result, values) {
+		t.Errorf("Expected a round trip for Rice parameter %d, got error %v", k, err)
+	}
+}
+
+func TestGolombErrors(t *testing.T) {
+	if _, err := EncodeRice(32, []uint32{}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+
+	if _, err := EncodeRice(0, []uint32{maxRiceQuotient + 1}); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	}
+
+	// A value of 0 followed by a quotient without its remainder.
+	if _, err := DecodeRice(4, []byte{0x84}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	_, err := DecodeRice(0, make([]byte, maxRiceQuotient/8+2))
+	if !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	} else if err.Error() != "quotient of more than 4096 zero bits" {
+		t.Errorf("Unexpected error message \"%s\"", err)
+	}
+
+	if _, err := DecodeExpGolomb(0, []byte{0x01}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	// 33 zeros are more than any 32-bit value needs.
+	if _, err := DecodeExpGolomb(0, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff}); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	}
+
+	// 32 zeros followed by a value just past 32 bits.
+	if _, err := DecodeExpGolomb(0, []byte{0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0x80}); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	}
+}
@@ -45,15 +45,24 @@ Args:
     are allocated to specify the length of the value and so the value
     may only be in the range of ints expressible in two bits (0..3)
     even though only at most one bit will be used to store the actual
-    value.
+    value. Widths marked with Signed hold zigzag mapped signed values.
   values: Ordered list of values. If a value exceeds the allocated
     space an error will be returned.
 */
 func Encode(fields []uint8, values []uint32) ([]byte, error) {
 	if len(fields) != len(values) {
-		return []byte{}, fmt.Errorf("mismatched field and value count, got %d fields and %d values", len(fields), len(values))
+		return []byte{}, countMismatchError(len(fields), len(values))
 	}
 
+	if err := validateFields(fields); err != nil {
+		return []byte{}, err
+	}
+
+	return encode(fields, values)
+}
+
+// Encode values whose count and field widths have already been validated.
+func encode(fields []uint8, values []uint32) ([]byte, error) {
 	var formatCurByte uint8
 	var formatCurIndex uint8
 	var valueCurByte uint8
@@ -65,11 +74,13 @@ func Encode(fields []uint8, values []uint32) ([]byte, error) {
 	totalValueWidth := 0
 
 	for i, fieldWidth := range fields {
-		if fieldWidth == 0 {
-			return []byte{}, fmt.Errorf("received invalid 0 field width")
+		value := values[i]
+		if fieldWidth&SignedField != 0 {
+			fieldWidth &^= SignedField
+			value = zigzagEncode32(value)
 		}
 
-		leadingZeros := countLeadingZeros(values[i])
+		leadingZeros := countLeadingZeros(value)
 		valueWidth := 32 - leadingZeros
 
 		// Zero value, nothing to add to value byte.
@@ -80,12 +91,12 @@ func Encode(fields []uint8, values []uint32) ([]byte, error) {
 		}
 
 		if valueWidth > (1<<fieldWidth)-1 {
-			return []byte{}, fmt.Errorf("value %d too large for field width %d", values[i], fieldWidth)
+			return []byte{}, newError(ErrValueTooLarge, i, uint64(values[i]), -1, "value %d too large for field width %d", values[i], fieldWidth)
 		}
 
 		addBitsToSlice(&formatResult, uint32(valueWidth), fieldWidth, &formatCurByte, &formatCurIndex, false)
 
-		addBitsToSlice(&valueResult, values[i], uint8(valueWidth), &valueCurByte, &valueCurIndex, true)
+		addBitsToSlice(&valueResult, value, uint8(valueWidth), &valueCurByte, &valueCurIndex, true)
 
 		totalValueWidth += valueWidth - 1
 	}
@@ -112,7 +123,25 @@ func Encode(fields []uint8, values []uint32) ([]byte, error) {
 	return formatResult, nil
 }
 
+// Return an error if any of the given field widths can not be used.
+func validateFields(fields []uint8) error {
+	for i, fieldWidth := range fields {
+		if fieldWidth&^SignedField == 0 {
+			return newError(ErrInvalidWidth, i, 0, -1, "received invalid 0 field width")
+		}
+	}
+
+	return nil
+}
+
 func Decode(fields []uint8, data []byte) ([]uint32, error) {
+	if len(data) == 0 {
+		if len(fields) == 0 {
+			return []uint32{}, nil
+		}
+		return []uint32{}, ErrEmpty
+	}
+
 	var curIndex uint8
 	curByte := data[0]
 	data = data[1:len(data)]
@@ -120,18 +149,32 @@ func Decode(fields []uint8, data []byte) ([]uint32, error) {
 	fieldWidths := make([]uint8, 0, len(fields))
 	values := make([]uint32, 0, len(fields))
 
-	for _, formatWidth := range fields {
-		curFieldWidth, err := popBitsFromSlice(&data, formatWidth, &curByte, &curIndex, false)
+	// Bit offset of the current width prefix or value, for errors.
+	bitOffset := 0
+
+	for i, formatWidth := range fields {
+		curFieldWidth, err := popBitsFromSlice(&data, formatWidth&^SignedField, &curByte, &curIndex, false)
 		if err != nil {
-			return []uint32{}, err
+			return []uint32{}, newError(ErrTruncated, i, 0, bitOffset, "%s", err)
+		}
+		if curFieldWidth > 32 {
+			return []uint32{}, newError(ErrInvalidWidth, i, uint64(curFieldWidth), bitOffset, "invalid value width %d for 32-bit values", curFieldWidth)
 		}
 		fieldWidths = append(fieldWidths, uint8(curFieldWidth))
+		bitOffset += int(formatWidth &^ SignedField)
 	}
 
-	for _, width := range fieldWidths {
+	for i, width := range fieldWidths {
 		curValue, err := popBitsFromSlice(&data, width, &curByte, &curIndex, true)
 		if err != nil {
-			return []uint32{}, err
+			return []uint32{}, newError(ErrTruncated, i, uint64(width), bitOffset, "%s", err)
+		}
+		if width > 0 {
+			bitOffset += int(width) - 1
+		}
+
+		if fields[i]&SignedField != 0 {
+			curValue = zigzagDecode32(curValue)
 		}
 
 		values = append(values, curValue)
@@ -140,6 +183,20 @@ func Decode(fields []uint8, data []byte) ([]uint32, error) {
 	return values, nil
 }
 
+// Decode the record at the start of data, which may be followed by further
+// records, and return its values along with the number of bytes it took up.
+// This allows walking records that were encoded back to back.
+func DecodeN(fields []uint8, data []byte) ([]uint32, int, error) {
+	values := make([]uint32, len(fields))
+
+	_, bits, err := decodeInto(values, fields, prefixBitsOf(fields), data)
+	if err != nil {
+		return []uint32{}, 0, err
+	}
+
+	return values, (bits + 7) / 8, nil
+}
+
 func popBitsFromSlice(slice *[]byte, width uint8, curByte *uint8, curIndex *uint8, addFirstBit bool) (uint32, error) {
 	if width == 0 {
 		return 0, nil
@@ -224,7 +281,7 @@ func popBitsFromSlice(slice *[]byte, width uint8, curByte *uint8, curIndex *uint
 
 		if remainingWidth != 0 {
 			if len(*slice) == 0 {
-				return 0, fmt.Errorf("ran out of data before end of value, expected additional %d bits of data", remainingWidth)
+				return 0, fmt.Errorf("%w before end of value, expected additional %d bits of data", ErrTruncated, remainingWidth)
 			}
 		}
 
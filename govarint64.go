@@ -0,0 +1,184 @@
+package govarint
+
+// Return the number of leading zeros before the first set bit of a 64-bit
+// value.
+func countLeadingZeros64(x uint64) int {
+	if x>>32 == 0 {
+		return 32 + countLeadingZeros(uint32(x))
+	}
+	return countLeadingZeros(uint32(x >> 32))
+}
+
+// Encode the given 64-bit values in the given varint format.
+//
+// The layout is identical to Encode, so values that fit in 32 bits produce
+// the same bytes as Encode would for the same fields. A field width of 7 is
+// needed to describe values using more than 32 bits. If a value exceeds the
+// space allocated by its field an error will be returned.
+func Encode64(fields []uint8, values []uint64) ([]byte, error) {
+	if len(fields) != len(values) {
+		return []byte{}, countMismatchError(len(fields), len(values))
+	}
+
+	if err := validateFields(fields); err != nil {
+		return []byte{}, err
+	}
+
+	return encode64(fields, values)
+}
+
+// Encode values whose count and field widths have already been validated.
+func encode64(fields []uint8, values []uint64) ([]byte, error) {
+	var formatCurByte uint8
+	var formatCurIndex uint8
+	var valueCurByte uint8
+	var valueCurIndex uint8
+
+	formatResult := make([]byte, 0, len(fields)*9)
+	valueResult := make([]byte, 0, len(fields)*8)
+
+	totalValueWidth := 0
+
+	for i, fieldWidth := range fields {
+		value := values[i]
+		if fieldWidth&SignedField != 0 {
+			fieldWidth &^= SignedField
+			value = zigzagEncode64(value)
+		}
+
+		leadingZeros := countLeadingZeros64(value)
+		valueWidth := 64 - leadingZeros
+
+		// Zero value, nothing to add to value byte.
+		if valueWidth == 0 {
+			addBitsToSlice(&formatResult, 0, fieldWidth, &formatCurByte, &formatCurIndex, false)
+
+			continue
+		}
+
+		if valueWidth > (1<<fieldWidth)-1 {
+			return []byte{}, newError(ErrValueTooLarge, i, values[i], -1, "value %d too large for field width %d", values[i], fieldWidth)
+		}
+
+		addBitsToSlice(&formatResult, uint32(valueWidth), fieldWidth, &formatCurByte, &formatCurIndex, false)
+
+		addBitsToSlice64(&valueResult, value, uint8(valueWidth), &valueCurByte, &valueCurIndex, true)
+
+		totalValueWidth += valueWidth - 1
+	}
+
+	// Add trailing value bits.
+	if valueCurIndex > 0 {
+		addBitsToSlice(&valueResult, 0, 8-valueCurIndex, &valueCurByte, &valueCurIndex, false)
+	}
+
+	for _, b := range valueResult {
+		if totalValueWidth < 8 {
+			addBitsToSlice(&formatResult, uint32(b>>uint(8-totalValueWidth)), uint8(totalValueWidth), &formatCurByte, &formatCurIndex, false)
+		} else {
+			addBitsToSlice(&formatResult, uint32(b), uint8(8), &formatCurByte, &formatCurIndex, false)
+			totalValueWidth -= 8
+		}
+	}
+
+	// Add trailing format bits.
+	if formatCurIndex > 0 {
+		addBitsToSlice(&formatResult, 0, 8-formatCurIndex, &formatCurByte, &formatCurIndex, false)
+	}
+
+	return formatResult, nil
+}
+
+func Decode64(fields []uint8, data []byte) ([]uint64, error) {
+	if len(data) == 0 {
+		if len(fields) == 0 {
+			return []uint64{}, nil
+		}
+		return []uint64{}, ErrEmpty
+	}
+
+	var curIndex uint8
+	curByte := data[0]
+	data = data[1:len(data)]
+
+	fieldWidths := make([]uint8, 0, len(fields))
+	values := make([]uint64, 0, len(fields))
+
+	// Bit offset of the current width prefix or value, for errors.
+	bitOffset := 0
+
+	for i, formatWidth := range fields {
+		curFieldWidth, err := popBitsFromSlice(&data, formatWidth&^SignedField, &curByte, &curIndex, false)
+		if err != nil {
+			return []uint64{}, newError(ErrTruncated, i, 0, bitOffset, "%s", err)
+		}
+		if curFieldWidth > 64 {
+			return []uint64{}, newError(ErrInvalidWidth, i, uint64(curFieldWidth), bitOffset, "invalid value width %d for 64-bit values", curFieldWidth)
+		}
+		fieldWidths = append(fieldWidths, uint8(curFieldWidth))
+		bitOffset += int(formatWidth &^ SignedField)
+	}
+
+	for i, width := range fieldWidths {
+		curValue, err := popBitsFromSlice64(&data, width, &curByte, &curIndex, true)
+		if err != nil {
+			return []uint64{}, newError(ErrTruncated, i, uint64(width), bitOffset, "%s", err)
+		}
+		if width > 0 {
+			bitOffset += int(width) - 1
+		}
+
+		if fields[i]&SignedField != 0 {
+			curValue = zigzagDecode64(curValue)
+		}
+
+		values = append(values, curValue)
+	}
+
+	return values, nil
+}
+
+// Decode the 64-bit record at the start of data, see DecodeN.
+func DecodeN64(fields []uint8, data []byte) ([]uint64, int, error) {
+	values := make([]uint64, len(fields))
+
+	_, bits, err := decodeInto64(values, fields, prefixBitsOf(fields), data)
+	if err != nil {
+		return []uint64{}, 0, err
+	}
+
+	return values, (bits + 7) / 8, nil
+}
+
+// Values wider than 32 bits are read as their high part followed by the
+// low 32 bits, so the 32-bit helper's 40-bit shift window is never exceeded.
+func popBitsFromSlice64(slice *[]byte, width uint8, curByte *uint8, curIndex *uint8, addFirstBit bool) (uint64, error) {
+	if width <= 32 {
+		value, err := popBitsFromSlice(slice, width, curByte, curIndex, addFirstBit)
+		return uint64(value), err
+	}
+
+	high, err := popBitsFromSlice(slice, width-32, curByte, curIndex, addFirstBit)
+	if err != nil {
+		return 0, err
+	}
+
+	low, err := popBitsFromSlice(slice, 32, curByte, curIndex, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(high)<<32 | uint64(low), nil
+}
+
+// Values wider than 32 bits are written as their high part followed by the
+// low 32 bits, see popBitsFromSlice64.
+func addBitsToSlice64(slice *[]byte, value uint64, width uint8, curByte *uint8, curIndex *uint8, skipFirstBit bool) {
+	if width <= 32 {
+		addBitsToSlice(slice, uint32(value), width, curByte, curIndex, skipFirstBit)
+		return
+	}
+
+	addBitsToSlice(slice, uint32(value>>32), width-32, curByte, curIndex, skipFirstBit)
+	addBitsToSlice(slice, uint32(value), 32, curByte, curIndex, false)
+}
@@ -0,0 +1,209 @@
+package govarint
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+type (
+	leadingZero64TestCase struct {
+		value uint64
+		count int
+	}
+)
+
+type roundTrip64TestCase struct {
+	fields []uint8
+	values []uint64
+}
+
+var (
+	roundTrip64Tests = []roundTrip64TestCase{
+		{[]uint8{1}, []uint64{0}},
+		{[]uint8{1}, []uint64{1}},
+		{[]uint8{3}, []uint64{8}},
+
+		{[]uint8{6}, []uint64{1<<32 - 1}},
+		{[]uint8{7}, []uint64{1 << 32}},
+		{[]uint8{7}, []uint64{1<<33 + 1}},
+		{[]uint8{7}, []uint64{1<<64 - 1}},
+
+		{[]uint8{4, 7}, []uint64{8, 12345678901234}},
+		{[]uint8{3, 7, 7}, []uint64{0, 1 << 63, 5}},
+
+		// Action type, actor type, actor ID, object type, object ID.
+		{[]uint8{3, 3, 7, 3, 7}, []uint64{1, 5, 5000000000, 2, 123456789012}},
+	}
+
+	leadingZero64Tests = []leadingZero64TestCase{
+		{0, 64},
+		{1, 63},
+		{1<<32 - 1, 32},
+		{1 << 32, 31},
+		{1<<33 - 1, 31},
+		{1 << 63, 0},
+		{1<<64 - 1, 0},
+	}
+)
+
+func TestCountLeadingZeros64(t *testing.T) {
+	for _, tc := range leadingZero64Tests {
+		count := countLeadingZeros64(tc.value)
+		if tc.count != count {
+			t.Errorf("Expected %d, got %d for %d", tc.count, count, tc.value)
+		}
+	}
+}
+
+func TestEncode64MatchesEncode(t *testing.T) {
+	for _, tc := range encodeTests {
+		values := make([]uint64, 0, len(tc.values))
+		for _, v := range tc.values {
+			values = append(values, uint64(v))
+		}
+
+		result, err := Encode64(tc.fields, values)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+	}
+}
+
+func TestInvalidEncode64(t *testing.T) {
+	_, err := Encode64([]uint8{6}, []uint64{1 << 63})
+
+	if err == nil {
+		t.Errorf("Did not receive expected error")
+		return
+	}
+
+	expected := "value 9223372036854775808 too large for field width 6"
+	if err.Error() != expected {
+		t.Errorf("Expected error \"%s\", got: %s", expected, err)
+	}
+}
+
+func TestRoundTrip64(t *testing.T) {
+	for _, tc := range roundTrip64Tests {
+		_, err := executeRoundTrip64(tc)
+		if err != nil {
+			t.Error(err.Error())
+		}
+	}
+}
+
+func executeRoundTrip64(tc roundTrip64TestCase) (uint, error) {
+	data, err := Encode64(tc.fields, tc.values)
+	if err != nil {
+		return 0, fmt.Errorf("Unexpected encode error \"%s\" for %v", err, tc)
+	}
+
+	size := uint(len(data))
+
+	result, err := Decode64(tc.fields, data)
+	if err != nil {
+		return 0, fmt.Errorf("Unexpected decode error \"%s\" for %v", err, tc)
+	}
+
+	if len(tc.values) != len(result) {
+		return 0, fmt.Errorf("Value count not equal, expected %d, got %d for %v", len(tc.values), len(result), tc)
+	}
+
+	for i, expected := range tc.values {
+		if expected != result[i] {
+			return 0, fmt.Errorf("Incorrect value, expected 0x%016x, got 0x%016x for %v", expected, result[i], tc)
+		}
+	}
+
+	return size, nil
+}
+
+func TestRandomRoundTrips64(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rand.Seed(seed)
+	fmt.Printf("Random seed: %d\n", seed)
+
+	var totalCustomSize uint
+	var totalStandardSize uint
+
+	for testCount := 0; testCount < 200000; testCount++ {
+		valueCount := int(rand.Int31n(30)) + 1
+
+		values := []uint64{}
+		fields := []uint8{}
+		for i := 0; i < valueCount; i++ {
+			curValue := rand.Uint64() & ((1 << uint(rand.Int31n(65))) - 1)
+			values = append(values, curValue)
+
+			valueLength := int32(64 - countLeadingZeros64(curValue))
+			fieldWidth := rand.Int31n(int32(64-valueLength+1)) + valueLength
+			fieldWidth = int32(32 - countLeadingZeros(uint32(fieldWidth)))
+			if fieldWidth == 0 {
+				fieldWidth = 1
+			}
+			fields = append(fields, uint8(fieldWidth))
+		}
+
+		tc := roundTrip64TestCase{fields, values}
+		customSize, err := executeRoundTrip64(tc)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		totalCustomSize += customSize
+		totalStandardSize += encodeStandardVarint64(tc)
+	}
+
+	fmt.Printf("Custom 64-bit varint would in total have used: %d bytes\n", totalCustomSize)
+	fmt.Printf("Standard library varint would in total have used: %d bytes\n", totalStandardSize)
+}
+
+func encodeStandardVarint64(tc roundTrip64TestCase) uint {
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	var size uint
+	for _, v := range tc.values {
+		size += uint(binary.PutUvarint(buf, v))
+	}
+
+	return size
+}
+
+func TestDecodeN64(t *testing.T) {
+	data := []byte{}
+	for _, tc := range roundTrip64Tests {
+		encoded, err := Encode64(tc.fields, tc.values)
+		if err != nil {
+			t.Fatalf("Unexpected encode error \"%s\" for %v", err, tc)
+		}
+		data = append(data, encoded...)
+	}
+
+	for _, tc := range roundTrip64Tests {
+		values, n, err := DecodeN64(tc.fields, data)
+		if err != nil {
+			t.Fatalf("Unexpected decode error \"%s\" for %v", err, tc)
+		}
+
+		for i, expected := range tc.values {
+			if expected != values[i] {
+				t.Errorf("Incorrect value, expected 0x%016x, got 0x%016x for %v", expected, values[i], tc)
+			}
+		}
+
+		data = data[n:]
+	}
+
+	if len(data) != 0 {
+		t.Errorf("Expected all data to be consumed, 0x%x left", data)
+	}
+}
@@ -12,12 +12,12 @@ import (
 )
 
 type Activity struct {
-	Version    uint8  `codec:version`
-	Action     uint8  `codec:action`
-	ActorType  uint8  `codec:actorType`
-	ActorID    uint32 `codec:actorID`
-	ObjectType uint8  `codec:objectType`
-	ObjectID   uint32 `codec:objectID`
+	Version    uint8  `govarint:"3" codec:version`
+	Action     uint8  `govarint:"3" codec:action`
+	ActorType  uint8  `govarint:"3" codec:actorType`
+	ActorID    uint32 `govarint:"6" codec:actorID`
+	ObjectType uint8  `govarint:"3" codec:objectType`
+	ObjectID   uint32 `govarint:"6" codec:objectID`
 }
 
 type (
@@ -286,6 +286,23 @@ func executeRoundTrip(tc roundTripTestCase) (uint, error) {
 	return size, nil
 }
 
+func executeGroupVarintRoundTrip(tc roundTripTestCase) (uint, error) {
+	data := EncodeGroupVarint(tc.values)
+
+	result, err := DecodeGroupVarint(len(tc.values), data)
+	if err != nil {
+		return 0, fmt.Errorf("Unexpected group varint decode error \"%s\" for %v", err, tc)
+	}
+
+	for i, expected := range tc.values {
+		if expected != result[i] {
+			return 0, fmt.Errorf("Incorrect group varint value, expected 0x%08x, got 0x%08x for %v", expected, result[i], tc)
+		}
+	}
+
+	return uint(len(data)), nil
+}
+
 func TestRandomRoundTrips(t *testing.T) {
 	seed := time.Now().UnixNano()
 	rand.Seed(seed)
@@ -322,8 +339,10 @@ func TestRandomRoundTrips(t *testing.T) {
 func compareRoundTrips(allCases []roundTripTestCase) error {
 	var totalCustomSize uint
 	var totalStandardSize uint
+	var totalGroupSize uint
 	var totalCustomTime int64
 	var totalStandardTime int64
+	var totalGroupTime int64
 
 	for _, tc := range allCases {
 		start := time.Now().UnixNano()
@@ -337,15 +356,26 @@ func compareRoundTrips(allCases []roundTripTestCase) error {
 		standardSize := encodeStandardVarint(tc)
 		totalStandardTime += time.Now().UnixNano() - start
 
+		start = time.Now().UnixNano()
+		groupSize, err := executeGroupVarintRoundTrip(tc)
+		totalGroupTime += time.Now().UnixNano() - start
+		if err != nil {
+			return err
+		}
+
 		totalCustomSize += customSize
 		totalStandardSize += standardSize
+		totalGroupSize += groupSize
 	}
 
 	fmt.Printf("Custom varint would in total have used: %d bytes\n", totalCustomSize)
 	fmt.Printf("Standard library varint would in total have used: %d bytes\n", totalStandardSize)
 	fmt.Printf("Custom varint total was %02f %% of standard library varint.\n", float32(totalCustomSize)/float32(totalStandardSize))
 
-	fmt.Printf("Custom varint took %s, standard library took %s.\n", time.Duration(totalCustomTime), time.Duration(totalStandardTime))
+	fmt.Printf("Group varint would in total have used: %d bytes\n", totalGroupSize)
+	fmt.Printf("Custom varint total was %02f %% of group varint.\n", float32(totalCustomSize)/float32(totalGroupSize))
+
+	fmt.Printf("Custom varint took %s, standard library took %s, group varint took %s.\n", time.Duration(totalCustomTime), time.Duration(totalStandardTime), time.Duration(totalGroupTime))
 
 	return nil
 }
@@ -503,6 +533,51 @@ func TestDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeN(t *testing.T) {
+	// Concatenate all records that share the same fields.
+	fields := []uint8{3, 1}
+	data := []byte{}
+	expected := [][]uint32{}
+	for _, tc := range encodeTests {
+		if bytes.Equal(tc.fields, fields) {
+			data = append(data, tc.result...)
+			expected = append(expected, tc.values)
+		}
+	}
+
+	for _, values := range expected {
+		result, n, err := DecodeN(fields, data)
+		if err != nil {
+			t.Fatalf("Unexpected decode error \"%s\" for 0x%x", err, data)
+		}
+
+		if n != 1 {
+			t.Errorf("Expected 1 byte consumed, got %d for 0x%x", n, data)
+		}
+
+		for i := range values {
+			if values[i] != result[i] {
+				t.Errorf("Incorrect value, expected 0x%08x, got 0x%08x for 0x%x", values[i], result[i], data)
+			}
+		}
+
+		data = data[n:]
+	}
+
+	if len(data) != 0 {
+		t.Errorf("Expected all data to be consumed, 0x%x left", data)
+	}
+
+	for _, tc := range decodeTests {
+		_, n, err := DecodeN(tc.fields, append(tc.data, 0xff, 0xff))
+		if err != nil {
+			t.Errorf("Unexpected decode error \"%s\" for %v", err, tc)
+		} else if n != len(tc.data) {
+			t.Errorf("Expected %d bytes consumed, got %d for %v", len(tc.data), n, tc)
+		}
+	}
+}
+
 func TestEncode(t *testing.T) {
 	for _, tc := range encodeTests {
 		result, err := Encode(tc.fields, tc.values)
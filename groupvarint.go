@@ -0,0 +1,73 @@
+package govarint
+
+// Encode values in Group Varint format, which stores them in groups of four
+// behind a tag byte holding the byte length of each value less one, two bits
+// per value with the first value in the top bits. Each value follows in as
+// many bytes as it needs, least significant byte first.
+//
+// Group Varint takes up more space than Encode since it works in whole
+// bytes, but decodes faster as no value straddles a byte boundary. Unused
+// tag bits of the last group are zero.
+func EncodeGroupVarint(values []uint32) []byte {
+	data := make([]byte, 0, (len(values)+3)/4+len(values)*4)
+
+	for i := 0; i < len(values); i += 4 {
+		tagIndex := len(data)
+		data = append(data, 0)
+
+		for j := 0; j < 4 && i+j < len(values); j++ {
+			value := values[i+j]
+
+			length := 1
+			for length < 4 && value>>(8*uint(length)) != 0 {
+				length++
+			}
+
+			data[tagIndex] |= uint8(length-1) << (6 - 2*uint(j))
+			for k := 0; k < length; k++ {
+				data = append(data, uint8(value>>(8*uint(k))))
+			}
+		}
+	}
+
+	return data
+}
+
+// Decode count values encoded with EncodeGroupVarint.
+func DecodeGroupVarint(count int, data []byte) ([]uint32, error) {
+	if count < 0 {
+		return []uint32{}, newError(ErrFieldCountMismatch, -1, 0, -1, "invalid value count %d", count)
+	}
+
+	// Every value takes up at least one byte.
+	if count > len(data) {
+		return []uint32{}, newError(ErrTruncated, -1, 0, len(data)*8, "ran out of data, expected at least %d bytes for %d values, got %d", count+(count+3)/4, count, len(data))
+	}
+
+	values := make([]uint32, count)
+	offset := 0
+	for i := 0; i < count; i += 4 {
+		if offset >= len(data) {
+			return []uint32{}, newError(ErrTruncated, i, 0, offset*8, "ran out of data before tag byte of value %d", i)
+		}
+		tag := data[offset]
+		offset++
+
+		for j := 0; j < 4 && i+j < count; j++ {
+			length := int(tag>>(6-2*uint(j))&0x3) + 1
+			if offset+length > len(data) {
+				return []uint32{}, newError(ErrTruncated, i+j, 0, offset*8, "ran out of data before end of value, expected %d bytes, got %d", length, len(data)-offset)
+			}
+
+			value := uint32(0)
+			for k := length - 1; k >= 0; k-- {
+				value = value<<8 | uint32(data[offset+k])
+			}
+
+			values[i+j] = value
+			offset += length
+		}
+	}
+
+	return values, nil
+}
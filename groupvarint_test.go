@@ -0,0 +1,78 @@
+package govarint
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type groupVarintTestCase struct {
+	values []uint32
+	result []byte
+}
+
+var (
+	groupVarintTests = []groupVarintTestCase{
+		{[]uint32{}, []byte{}},
+		{[]uint32{0}, []byte{0x00, 0x00}},
+		{[]uint32{1, 256, 65536, 16777216}, []byte{0x1b, 0x01, 0x00, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01}},
+		{[]uint32{0xffffffff, 0, 0, 0, 0x1234}, []byte{0xc0, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x40, 0x34, 0x12}},
+	}
+)
+
+func TestEncodeGroupVarint(t *testing.T) {
+	for _, tc := range groupVarintTests {
+		result := EncodeGroupVarint(tc.values)
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+
+		values, err := DecodeGroupVarint(len(tc.values), result)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("Expected %v, got %v for %v", tc.values, values, tc)
+		}
+	}
+}
+
+func TestGroupVarintRoundTrip(t *testing.T) {
+	for testCount := 0; testCount < 100000; testCount++ {
+		values := make([]uint32, rand.Int31n(30))
+		for i := range values {
+			values[i] = uint32(rand.Int63() & ((1 << uint(rand.Int31n(33))) - 1))
+		}
+
+		result, err := DecodeGroupVarint(len(values), EncodeGroupVarint(values))
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		if !reflect.DeepEqual(result, values) {
+			t.Fatalf("Expected %v, got %v", values, result)
+		}
+	}
+}
+
+func TestDecodeGroupVarintErrors(t *testing.T) {
+	if _, err := DecodeGroupVarint(-1, []byte{}); !errors.Is(err, ErrFieldCountMismatch) {
+		t.Errorf("Expected ErrFieldCountMismatch, got %v", err)
+	}
+
+	if _, err := DecodeGroupVarint(2, []byte{0x00}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if _, err := DecodeGroupVarint(2, []byte{0x40, 0x01, 0x02}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if _, err := DecodeGroupVarint(5, []byte{0x00, 0x01, 0x02, 0x03, 0x04}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+}
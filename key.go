@@ -0,0 +1,161 @@
+package govarint
+
+// Encode values so that comparing two encoded keys with bytes.Compare
+// orders them like comparing their values field by field. The result can be
+// used as a sorted key in LevelDB or Bolt style stores.
+//
+// Unlike Encode, which writes all width prefixes first, each width prefix is
+// followed directly by its value, so the first field that differs between
+// two keys decides their order. Signed fields are ordered as signed integers
+// and take up one extra bit for the sign: non-negative values are written
+// after a 1 bit, negative values after a 0 bit with the prefix and value of
+// -value-1 inverted.
+func EncodeKey(fields []uint8, values []uint32) ([]byte, error) {
+	if len(fields) != len(values) {
+		return []byte{}, countMismatchError(len(fields), len(values))
+	}
+
+	values64 := make([]uint64, len(values))
+	for i, value := range values {
+		if fields[i]&SignedField != 0 {
+			values64[i] = uint64(int64(int32(value)))
+		} else {
+			values64[i] = uint64(value)
+		}
+	}
+
+	return encodeKey(fields, values64)
+}
+
+// Encode 64-bit values as a sorted key, see EncodeKey.
+func EncodeKey64(fields []uint8, values []uint64) ([]byte, error) {
+	if len(fields) != len(values) {
+		return []byte{}, countMismatchError(len(fields), len(values))
+	}
+
+	return encodeKey(fields, values)
+}
+
+// Decode a key produced by EncodeKey. Like DecodeStrict, keys with non-zero
+// padding or trailing data are rejected.
+func DecodeKey(fields []uint8, data []byte) ([]uint32, error) {
+	values64, err := decodeKey(fields, data, 32)
+	if err != nil {
+		return []uint32{}, err
+	}
+
+	values := make([]uint32, len(values64))
+	for i, value := range values64 {
+		values[i] = uint32(value)
+	}
+
+	return values, nil
+}
+
+// Decode a key produced by EncodeKey64.
+func DecodeKey64(fields []uint8, data []byte) ([]uint64, error) {
+	values, err := decodeKey(fields, data, 64)
+	if err != nil {
+		return []uint64{}, err
+	}
+
+	return values, nil
+}
+
+func encodeKey(fields []uint8, values []uint64) ([]byte, error) {
+	if err := validateFields(fields); err != nil {
+		return []byte{}, err
+	}
+
+	w := NewBitWriter(make([]byte, 0, MaxEncodedSize64(fields)+len(fields)/8+1))
+	for i, fieldWidth := range fields {
+		value := values[i]
+
+		// Negative values are written as the inverted bits of -value-1, so
+		// that larger magnitudes sort first.
+		invert := uint64(0)
+		if fieldWidth&SignedField != 0 {
+			fieldWidth &^= SignedField
+			if int64(value) < 0 {
+				value = ^value
+				invert = ^uint64(0)
+				w.WriteBits(0, 1)
+			} else {
+				w.WriteBits(1, 1)
+			}
+		}
+
+		valueWidth := uint8(64 - countLeadingZeros64(value))
+		if int(valueWidth) > (1<<fieldWidth)-1 {
+			return []byte{}, newError(ErrValueTooLarge, i, values[i], -1, "value %d too large for field width %d", values[i], fieldWidth)
+		}
+
+		w.WriteBits(uint64(valueWidth)^invert, fieldWidth)
+		if valueWidth > 1 {
+			w.WriteBits(value^invert, valueWidth-1)
+		}
+	}
+
+	return w.Bytes(), nil
+}
+
+// Decode a key holding values of at most valueBits bits. Values of signed
+// fields are returned as their two's complement.
+func decodeKey(fields []uint8, data []byte, valueBits uint) ([]uint64, error) {
+	if err := checkNotEmpty(fields, data); err != nil {
+		return nil, err
+	}
+
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	values := make([]uint64, len(fields))
+	r := NewBitReader(data)
+	for i, fieldWidth := range fields {
+		bitOffset := r.Offset()
+
+		invert := uint64(0)
+		maxWidth := valueBits
+		if fieldWidth&SignedField != 0 {
+			fieldWidth &^= SignedField
+			maxWidth--
+
+			sign, err := r.ReadBits(1)
+			if err != nil {
+				return nil, newError(ErrTruncated, i, 0, bitOffset, "ran out of data before end of sign bit")
+			}
+			if sign == 0 {
+				invert = ^uint64(0)
+			}
+		}
+
+		width, err := r.ReadBits(fieldWidth)
+		if err != nil {
+			return nil, newError(ErrTruncated, i, 0, r.Offset(), "ran out of data before end of width prefix, expected %d bits of data, got %d", fieldWidth, r.Remaining())
+		}
+
+		width = (width ^ invert) & (1<<fieldWidth - 1)
+		if width > uint64(maxWidth) {
+			return nil, newError(ErrInvalidWidth, i, width, bitOffset, "invalid value width %d for %d-bit values", width, valueBits)
+		}
+
+		value := uint64(0)
+		if width > 0 {
+			bits, err := r.ReadBits(uint8(width - 1))
+			if err != nil {
+				return nil, newError(ErrTruncated, i, width, r.Offset(), "ran out of data before end of value, expected additional %d bits of data", int(width)-1-r.Remaining())
+			}
+
+			value = uint64(1)<<(width-1) | (bits^invert)&(uint64(1)<<(width-1)-1)
+		}
+
+		values[i] = value ^ invert
+	}
+
+	if err := checkRecordEnd(data, r.Offset()); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,142 @@
+package govarint
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type keyTestCase struct {
+	fields []uint8
+	values []uint32
+	result []byte
+}
+
+var (
+	keyTests = []keyTestCase{
+		{[]uint8{1}, []uint32{0}, []byte{0x00}},
+		{[]uint8{1}, []uint32{1}, []byte{0x80}},
+		{[]uint8{3, 3}, []uint32{5, 1}, []byte{0x69}},
+		{[]uint8{Signed(2)}, []uint32{0}, []byte{0x80}},
+		{[]uint8{Signed(2)}, []uint32{1}, []byte{0xa0}},
+		{[]uint8{Signed(2)}, []uint32{0xffffffff}, []byte{0x60}},
+		{[]uint8{Signed(2)}, []uint32{0xfffffffe}, []byte{0x40}},
+	}
+)
+
+func TestEncodeKey(t *testing.T) {
+	for _, tc := range keyTests {
+		result, err := EncodeKey(tc.fields, tc.values)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+
+		values, err := DecodeKey(tc.fields, result)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("Expected %v, got %v for %v", tc.values, values, tc)
+		}
+	}
+}
+
+// Return -1, 0 or 1 as a is lower than, equal to or higher than b, comparing
+// signed fields as signed integers.
+func compareTuples(fields []uint8, a []uint64, b []uint64) int {
+	for i := range a {
+		if a[i] == b[i] {
+			continue
+		}
+
+		if fields[i]&SignedField != 0 {
+			if int64(a[i]) < int64(b[i]) {
+				return -1
+			}
+			return 1
+		}
+
+		if a[i] < b[i] {
+			return -1
+		}
+		return 1
+	}
+
+	return 0
+}
+
+func TestEncodeKeyOrder(t *testing.T) {
+	fields := []uint8{3, Signed(7), 7}
+
+	randomValue := func() uint64 {
+		// Keep values small often so that ties on earlier fields are common.
+		return rand.Uint64() & ((1 << uint(rand.Int31n(65))) - 1) & ((1 << uint(rand.Int31n(65))) - 1)
+	}
+
+	for testCount := 0; testCount < 100000; testCount++ {
+		a := []uint64{uint64(rand.Int31n(4)), randomValue(), randomValue()}
+		b := []uint64{uint64(rand.Int31n(4)), randomValue(), randomValue()}
+		if rand.Int31n(2) == 0 {
+			b[1] = a[1]
+		}
+
+		keyA, err := EncodeKey64(fields, a)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, a)
+		}
+
+		keyB, err := EncodeKey64(fields, b)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, b)
+		}
+
+		if expected, result := compareTuples(fields, a, b), bytes.Compare(keyA, keyB); expected != result {
+			t.Fatalf("Expected order %d, got %d for %v and %v", expected, result, a, b)
+		}
+
+		values, err := DecodeKey64(fields, keyA)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, a)
+		}
+
+		if !reflect.DeepEqual(values, a) {
+			t.Fatalf("Expected %v, got %v", a, values)
+		}
+	}
+}
+
+func TestDecodeKeyErrors(t *testing.T) {
+	if _, err := EncodeKey([]uint8{2}, []uint32{8}); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	}
+
+	if _, err := EncodeKey([]uint8{2}, []uint32{}); !errors.Is(err, ErrFieldCountMismatch) {
+		t.Errorf("Expected ErrFieldCountMismatch, got %v", err)
+	}
+
+	if _, err := DecodeKey([]uint8{3}, []byte{}); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+
+	if _, err := DecodeKey([]uint8{3, 3}, []byte{0x6c}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if _, err := DecodeKey([]uint8{3}, []byte{0x01}); !errors.Is(err, ErrNonZeroPadding) {
+		t.Errorf("Expected ErrNonZeroPadding, got %v", err)
+	}
+
+	// Width 33 does not fit in 32 bits.
+	if _, err := DecodeKey([]uint8{6}, []byte{0x84, 0, 0, 0, 0}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+}
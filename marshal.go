@@ -0,0 +1,158 @@
+package govarint
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// The layout of a struct type, built from its govarint tags.
+type structLayout struct {
+	schema *Schema
+	// Index of the struct field backing each schema field.
+	index []int
+}
+
+// Layouts by struct type, filled in the first time a type is marshalled.
+var layoutCache sync.Map
+
+// Encode the tagged fields of the given struct, or pointer to struct, in
+// the varint format.
+//
+// Fields are encoded in declaration order and take their width from a
+// govarint struct tag, e.g.:
+//
+//	type Activity struct {
+//		Version uint8  `govarint:"3"`
+//		ActorID uint32 `govarint:"6"`
+//	}
+//
+// Fields without the tag are ignored. Unsigned integer and bool fields are
+// supported, as are signed integer fields which are encoded as signed
+// fields, see Signed.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return []byte{}, fmt.Errorf("cannot marshal nil pointer")
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return []byte{}, fmt.Errorf("cannot marshal %s, expected struct", rv.Type())
+	}
+
+	layout, err := layoutOf(rv.Type())
+	if err != nil {
+		return []byte{}, err
+	}
+
+	values := make([]uint64, len(layout.index))
+	for i, index := range layout.index {
+		field := rv.Field(index)
+		switch field.Kind() {
+		case reflect.Bool:
+			if field.Bool() {
+				values[i] = 1
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			values[i] = uint64(field.Int())
+		default:
+			values[i] = field.Uint()
+		}
+	}
+
+	return layout.schema.Encode64(values)
+}
+
+// Decode a record produced by Marshal into the struct pointed to by v.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal into %T, expected pointer to struct", v)
+	}
+	rv = rv.Elem()
+
+	layout, err := layoutOf(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	values, err := layout.schema.Decode64(data)
+	if err != nil {
+		return err
+	}
+
+	for i, index := range layout.index {
+		field := rv.Field(index)
+		name := rv.Type().Field(index).Name
+
+		switch field.Kind() {
+		case reflect.Bool:
+			if values[i] > 1 {
+				return fmt.Errorf("value %d overflows bool field %s", values[i], name)
+			}
+			field.SetBool(values[i] == 1)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if field.OverflowInt(int64(values[i])) {
+				return fmt.Errorf("value %d overflows %s field %s", int64(values[i]), field.Type(), name)
+			}
+			field.SetInt(int64(values[i]))
+		default:
+			if field.OverflowUint(values[i]) {
+				return fmt.Errorf("value %d overflows %s field %s", values[i], field.Type(), name)
+			}
+			field.SetUint(values[i])
+		}
+	}
+
+	return nil
+}
+
+func layoutOf(t reflect.Type) (*structLayout, error) {
+	if layout, ok := layoutCache.Load(t); ok {
+		return layout.(*structLayout), nil
+	}
+
+	fields := []uint8{}
+	index := []int{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("govarint")
+		if !ok {
+			continue
+		}
+
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("cannot marshal unexported field %s of %s", field.Name, t)
+		}
+
+		width, err := strconv.ParseUint(tag, 10, 8)
+		if err != nil || uint8(width)&SignedField != 0 {
+			return nil, fmt.Errorf("invalid govarint tag %q on field %s of %s", tag, field.Name, t)
+		}
+
+		fieldWidth := uint8(width)
+		switch field.Type.Kind() {
+		case reflect.Bool, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fieldWidth = Signed(fieldWidth)
+		default:
+			return nil, fmt.Errorf("cannot marshal field %s of %s with kind %s", field.Name, t, field.Type.Kind())
+		}
+
+		fields = append(fields, fieldWidth)
+		index = append(index, i)
+	}
+
+	schema, err := NewSchema64(fields...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid layout for %s: %s", t, err)
+	}
+
+	layout, _ := layoutCache.LoadOrStore(t, &structLayout{schema, index})
+	return layout.(*structLayout), nil
+}
@@ -0,0 +1,145 @@
+package govarint
+
+import (
+	"bytes"
+	"testing"
+)
+
+type signedRecord struct {
+	Delta   int32  `govarint:"6"`
+	Offset  int64  `govarint:"7"`
+	Deleted bool   `govarint:"1"`
+	Skipped string // Untagged fields are ignored.
+	Count   uint16 `govarint:"5"`
+}
+
+type narrowRecord struct {
+	Small uint8 `govarint:"4"`
+}
+
+type wideRecord struct {
+	Small uint16 `govarint:"4"`
+}
+
+type badTagRecord struct {
+	Value uint32 `govarint:"six"`
+}
+
+type badKindRecord struct {
+	Value float64 `govarint:"6"`
+}
+
+type wideTagRecord struct {
+	Value uint32 `govarint:"8"`
+}
+
+func TestMarshalActivity(t *testing.T) {
+	a := Activity{
+		Version:    1,
+		Action:     5,
+		ActorType:  2,
+		ActorID:    1128411,
+		ObjectType: 3,
+		ObjectID:   123456789,
+	}
+
+	data, err := Marshal(&a)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected, err := Encode([]uint8{3, 3, 3, 6, 3, 6}, []uint32{1, 5, 2, 1128411, 3, 123456789})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Expected 0x%x, got 0x%x", expected, data)
+	}
+
+	newA := Activity{}
+	if err := Unmarshal(data, &newA); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if a != newA {
+		t.Errorf("Expected %v, got %v", a, newA)
+	}
+}
+
+func TestMarshalSigned(t *testing.T) {
+	r := signedRecord{Delta: -12345, Offset: -1 << 40, Deleted: true, Skipped: "x", Count: 1000}
+
+	data, err := Marshal(r)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	newR := signedRecord{}
+	if err := Unmarshal(data, &newR); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	r.Skipped = ""
+	if r != newR {
+		t.Errorf("Expected %v, got %v", r, newR)
+	}
+}
+
+func TestUnmarshalOverflow(t *testing.T) {
+	data, err := Marshal(wideRecord{Small: 1000})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	err = Unmarshal(data, &narrowRecord{})
+	if err == nil {
+		t.Fatalf("Did not receive expected error")
+	}
+
+	expected := "value 1000 overflows uint8 field Small"
+	if err.Error() != expected {
+		t.Errorf("Expected error \"%s\", got: %s", expected, err)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	if _, err := Marshal(5); err == nil {
+		t.Errorf("Expected error marshalling non-struct")
+	}
+
+	if _, err := Marshal((*Activity)(nil)); err == nil {
+		t.Errorf("Expected error marshalling nil pointer")
+	}
+
+	if err := Unmarshal([]byte{0}, Activity{}); err == nil {
+		t.Errorf("Expected error unmarshalling into non-pointer")
+	}
+
+	if _, err := Marshal(badTagRecord{}); err == nil {
+		t.Errorf("Expected error for invalid tag")
+	}
+
+	if _, err := Marshal(badKindRecord{}); err == nil {
+		t.Errorf("Expected error for unsupported kind")
+	}
+
+	if _, err := Marshal(wideTagRecord{}); err == nil {
+		t.Errorf("Expected error for too wide field")
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	a := Activity{1, 5, 2, 1128411, 3, 123456789}
+
+	for i := 0; i < b.N; i++ {
+		data, err := Marshal(&a)
+		if err != nil {
+			b.Fatal(err.Error())
+		}
+
+		if err := Unmarshal(data, &a); err != nil {
+			b.Fatal(err.Error())
+		}
+	}
+}
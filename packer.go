@@ -0,0 +1,231 @@
+package govarint
+
+// A Packer builds a single record one field at a time, producing the same
+// bytes as Encode64 would for the same fields and values. It is meant for
+// generated code that knows its fields up front and so has no need for an
+// intermediate values slice.
+//
+// The zero value is ready to use. Call Reset before packing another record.
+type Packer struct {
+	format      []byte
+	formatByte  uint8
+	formatIndex uint8
+	value       []byte
+	valueByte   uint8
+	valueIndex  uint8
+	valueBits   int
+	// Number of fields added, for errors.
+	fieldCount int
+}
+
+// Clear the packer so that a new record can be packed, keeping its buffers.
+func (p *Packer) Reset() {
+	p.format = p.format[:0]
+	p.formatByte = 0
+	p.formatIndex = 0
+	p.value = p.value[:0]
+	p.valueByte = 0
+	p.valueIndex = 0
+	p.valueBits = 0
+	p.fieldCount = 0
+}
+
+// Add an unsigned value in a field of the given width.
+func (p *Packer) PutUint(fieldWidth uint8, value uint64) error {
+	if !p.put(fieldWidth, value) {
+		return newError(ErrValueTooLarge, p.fieldCount, value, -1, "value %d too large for field width %d", value, fieldWidth)
+	}
+
+	return nil
+}
+
+// Add a signed value in a field of the given width, see Signed.
+func (p *Packer) PutInt(fieldWidth uint8, value int64) error {
+	if !p.put(fieldWidth, zigzagEncode64(uint64(value))) {
+		return newError(ErrValueTooLarge, p.fieldCount, uint64(value), -1, "value %d too large for field width %d", value, fieldWidth)
+	}
+
+	return nil
+}
+
+// Add a bool in a field of the given width, stored as 0 or 1.
+func (p *Packer) PutBool(fieldWidth uint8, value bool) error {
+	if value {
+		return p.PutUint(fieldWidth, 1)
+	}
+
+	return p.PutUint(fieldWidth, 0)
+}
+
+func (p *Packer) put(fieldWidth uint8, value uint64) bool {
+	valueWidth := 64 - countLeadingZeros64(value)
+
+	if valueWidth > (1<<fieldWidth)-1 {
+		return false
+	}
+	p.fieldCount++
+
+	// Zero value, nothing to add to value byte.
+	if valueWidth == 0 {
+		addBitsToSlice(&p.format, 0, fieldWidth, &p.formatByte, &p.formatIndex, false)
+		return true
+	}
+
+	addBitsToSlice(&p.format, uint32(valueWidth), fieldWidth, &p.formatByte, &p.formatIndex, false)
+	addBitsToSlice64(&p.value, value, uint8(valueWidth), &p.valueByte, &p.valueIndex, true)
+	p.valueBits += valueWidth - 1
+
+	return true
+}
+
+// Return the encoded record. The packer keeps its state, so further fields
+// may still be added and Bytes called again.
+func (p *Packer) Bytes() []byte {
+	result := make([]byte, len(p.format), len(p.format)+len(p.value)+2)
+	copy(result, p.format)
+	curByte := p.formatByte
+	curIndex := p.formatIndex
+
+	value := p.value
+	if p.valueIndex > 0 {
+		value = append(value[:len(value):len(value)], p.valueByte)
+	}
+
+	totalValueWidth := p.valueBits
+	for _, b := range value {
+		if totalValueWidth < 8 {
+			addBitsToSlice(&result, uint32(b>>uint(8-totalValueWidth)), uint8(totalValueWidth), &curByte, &curIndex, false)
+		} else {
+			addBitsToSlice(&result, uint32(b), uint8(8), &curByte, &curIndex, false)
+			totalValueWidth -= 8
+		}
+	}
+
+	// Add trailing format bits.
+	if curIndex > 0 {
+		addBitsToSlice(&result, 0, 8-curIndex, &curByte, &curIndex, false)
+	}
+
+	return result
+}
+
+// An Unpacker reads the fields of a single record in order, the counterpart
+// of Packer.
+type Unpacker struct {
+	format      []byte
+	formatByte  uint8
+	formatIndex uint8
+	value       []byte
+	valueByte   uint8
+	valueIndex  uint8
+	// Bit offsets of the next width prefix, the last value read and the
+	// next value bit, and the total number of bits.
+	prefixPos  int
+	valueStart int
+	valuePos   int
+	totalBits  int
+	// Number of fields read, for errors.
+	fieldCount int
+}
+
+// Start reading the given record. The values of a record follow the width
+// prefixes of all its fields, so the sum of the field widths is needed to
+// locate them.
+func (u *Unpacker) Reset(data []byte, prefixBits int) error {
+	if prefixBits > len(data)*8 {
+		return newError(ErrTruncated, -1, 0, len(data)*8, "ran out of data before end of width prefixes, expected %d bits of data, got %d", prefixBits, len(data)*8)
+	}
+
+	u.format, u.formatByte, u.formatIndex = cursorAt(data, 0)
+	u.value, u.valueByte, u.valueIndex = cursorAt(data, prefixBits)
+	u.prefixPos = 0
+	u.valuePos = prefixBits
+	u.totalBits = len(data) * 8
+	u.fieldCount = 0
+
+	return nil
+}
+
+// Read an unsigned value from a field of the given width, checking that it
+// fits in the given number of bits.
+func (u *Unpacker) Uint(fieldWidth uint8, bits int) (uint64, error) {
+	value, err := u.next(fieldWidth, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if bits < 64 && value>>uint(bits) != 0 {
+		return 0, newError(ErrValueTooLarge, u.fieldCount-1, value, u.valueStart, "value %d overflows %d bits", value, bits)
+	}
+
+	return value, nil
+}
+
+// Read a signed value from a field of the given width, checking that it
+// fits in the given number of bits.
+func (u *Unpacker) Int(fieldWidth uint8, bits int) (int64, error) {
+	value, err := u.next(fieldWidth, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	signed := int64(zigzagDecode64(value))
+	if bits < 64 && (signed < -1<<uint(bits-1) || signed >= 1<<uint(bits-1)) {
+		return 0, newError(ErrValueTooLarge, u.fieldCount-1, value, u.valueStart, "value %d overflows %d bits", signed, bits)
+	}
+
+	return signed, nil
+}
+
+// Read a bool from a field of the given width.
+func (u *Unpacker) Bool(fieldWidth uint8) (bool, error) {
+	value, err := u.Uint(fieldWidth, 1)
+	return value == 1, err
+}
+
+// Read the next value, which must be at most valueBits wide.
+func (u *Unpacker) next(fieldWidth uint8, valueBits uint) (uint64, error) {
+	field := u.fieldCount
+	u.fieldCount++
+
+	width, err := popBitsFromSlice(&u.format, fieldWidth, &u.formatByte, &u.formatIndex, false)
+	if err != nil {
+		return 0, newError(ErrTruncated, field, 0, u.prefixPos, "%s", err)
+	}
+
+	if uint(width) > valueBits {
+		return 0, newError(ErrInvalidWidth, field, uint64(width), u.prefixPos, "invalid value width %d for %d-bit values", width, valueBits)
+	}
+	u.prefixPos += int(fieldWidth)
+	u.valueStart = u.valuePos
+
+	if width == 0 {
+		return 0, nil
+	}
+
+	if u.valuePos+int(width)-1 > u.totalBits {
+		return 0, newError(ErrTruncated, field, uint64(width), u.valuePos, "ran out of data before end of value, expected additional %d bits of data", u.valuePos+int(width)-1-u.totalBits)
+	}
+	u.valuePos += int(width) - 1
+
+	return popBitsFromSlice64(&u.value, uint8(width), &u.valueByte, &u.valueIndex, true)
+}
+
+// Return the slice, current byte and index for reading data from the given
+// bit offset with popBitsFromSlice.
+func cursorAt(data []byte, bitOffset int) ([]byte, uint8, uint8) {
+	i := bitOffset / 8
+	if i >= len(data) {
+		return []byte{}, 0, 0
+	}
+
+	return data[i+1:], data[i], uint8(bitOffset % 8)
+}
+
+// Return the width bits at the given bit offset of data, which the caller
+// has checked are there.
+func getBits(data []byte, bitOffset int, width uint) uint64 {
+	slice, curByte, curIndex := cursorAt(data, bitOffset)
+	value, _ := popBitsFromSlice64(&slice, uint8(width), &curByte, &curIndex, false)
+	return value
+}
@@ -0,0 +1,103 @@
+package govarint
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPackerMatchesEncode(t *testing.T) {
+	var p Packer
+
+	for _, tc := range encodeTests {
+		p.Reset()
+
+		for i, fieldWidth := range tc.fields {
+			if err := p.PutUint(fieldWidth, uint64(tc.values[i])); err != nil {
+				t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			}
+		}
+
+		result := p.Bytes()
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+	}
+}
+
+func TestPackerBytesKeepsState(t *testing.T) {
+	var p Packer
+
+	p.PutUint(4, 1)
+	first := p.Bytes()
+	p.PutUint(5, 12345)
+
+	if !bytes.Equal(first, []byte{0x10}) {
+		t.Errorf("Expected 0x10, got 0x%x", first)
+	}
+
+	expected := []byte{0x17, 0x40, 0xe4}
+	if result := p.Bytes(); !bytes.Equal(result, expected) {
+		t.Errorf("Expected 0x%x, got 0x%x", expected, result)
+	}
+}
+
+func TestUnpacker(t *testing.T) {
+	var p Packer
+	p.PutUint(3, 5)
+	p.PutInt(6, -123456)
+	p.PutBool(1, true)
+	p.PutUint(7, 1<<40)
+	data := p.Bytes()
+
+	var u Unpacker
+	if err := u.Reset(data, 3+6+1+7); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if v, err := u.Uint(3, 8); err != nil || v != 5 {
+		t.Errorf("Expected 5, got %d, %v", v, err)
+	}
+	if v, err := u.Int(6, 32); err != nil || v != -123456 {
+		t.Errorf("Expected -123456, got %d, %v", v, err)
+	}
+	if v, err := u.Bool(1); err != nil || !v {
+		t.Errorf("Expected true, got %t, %v", v, err)
+	}
+	if v, err := u.Uint(7, 64); err != nil || v != 1<<40 {
+		t.Errorf("Expected %d, got %d, %v", uint64(1<<40), v, err)
+	}
+}
+
+func TestUnpackerOverflow(t *testing.T) {
+	var p Packer
+	p.PutUint(4, 256)
+	p.PutInt(4, -129)
+
+	var u Unpacker
+	u.Reset(p.Bytes(), 8)
+
+	if _, err := u.Uint(4, 8); err == nil {
+		t.Errorf("Expected overflow error")
+	}
+	if _, err := u.Int(4, 8); err == nil {
+		t.Errorf("Expected overflow error")
+	}
+}
+
+func TestUnpackerTruncated(t *testing.T) {
+	var u Unpacker
+	if err := u.Reset([]byte{0x17}, 9); err == nil {
+		t.Errorf("Expected error for missing width prefixes")
+	}
+
+	// The second value needs 14 more bits than there are.
+	if err := u.Reset([]byte{0x17, 0x40}, 9); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, err := u.Uint(4, 32); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if _, err := u.Uint(5, 32); err == nil {
+		t.Errorf("Expected error for truncated value")
+	}
+}
@@ -0,0 +1,93 @@
+package govarint
+
+import (
+	"encoding/binary"
+)
+
+// Maximum lengths of a PrefixVarint encoded 32-bit and 64-bit value.
+const (
+	MaxPrefixVarintLen32 = 5
+	MaxPrefixVarintLen64 = 9
+)
+
+// Length of a PrefixVarint by its first byte.
+var prefixVarintLengths = func() (table [256]uint8) {
+	for b := range table {
+		// Leading ones of the first byte are leading zeros of its
+		// complement.
+		table[b] = uint8(countLeadingZeros(uint32(^uint8(b))<<24) + 1)
+	}
+	table[0xff] = MaxPrefixVarintLen64
+	return table
+}()
+
+// Encode x into buf as a PrefixVarint and return the number of bytes
+// written. Panics if buf is too small, like binary.PutUvarint.
+//
+// The number of leading 1 bits in the first byte, up to the first 0 bit,
+// gives the number of bytes that follow it, so the length is known from the
+// first byte alone. The value is stored big-endian in the remaining bits,
+// which gives 7 bits per byte up to 8 bytes and all 64 bits after a first
+// byte of 0xff. Since shorter encodings hold smaller values, comparing
+// encoded values byte-wise orders them numerically.
+func PutPrefixVarint(buf []byte, x uint64) int {
+	n := (64 - countLeadingZeros64(x) + 6) / 7
+	if n == 0 {
+		n = 1
+	}
+
+	if n > 8 {
+		buf[0] = 0xff
+		binary.BigEndian.PutUint64(buf[1:], x)
+		return MaxPrefixVarintLen64
+	}
+
+	for i := n - 1; i > 0; i-- {
+		buf[i] = uint8(x)
+		x >>= 8
+	}
+	buf[0] = uint8(x) | ^uint8(0xff>>uint(n-1))
+
+	return n
+}
+
+// Encode a 32-bit value as a PrefixVarint, see PutPrefixVarint.
+func PutPrefixVarint32(buf []byte, x uint32) int {
+	return PutPrefixVarint(buf, uint64(x))
+}
+
+// Decode a PrefixVarint from buf and return it along with the number of
+// bytes read. Like binary.Uvarint, n is 0 if buf is too small.
+func PrefixVarint(buf []byte) (uint64, int) {
+	if len(buf) == 0 {
+		return 0, 0
+	}
+
+	n := int(prefixVarintLengths[buf[0]])
+	if len(buf) < n {
+		return 0, 0
+	}
+
+	if n == MaxPrefixVarintLen64 {
+		return binary.BigEndian.Uint64(buf[1:]), n
+	}
+
+	x := uint64(buf[0] & (0xff >> uint(n)))
+	for _, b := range buf[1:n] {
+		x = x<<8 | uint64(b)
+	}
+
+	return x, n
+}
+
+// Decode a PrefixVarint holding a 32-bit value, see PrefixVarint. If the
+// value does not fit in 32 bits, n is the negated number of bytes read,
+// like binary.Uvarint does on overflow.
+func PrefixVarint32(buf []byte) (uint32, int) {
+	x, n := PrefixVarint(buf)
+	if n > 0 && x>>32 != 0 {
+		return 0, -n
+	}
+
+	return uint32(x), n
+}
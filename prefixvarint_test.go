@@ -0,0 +1,147 @@
+package govarint
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+)
+
+type prefixVarintTestCase struct {
+	value  uint64
+	result []byte
+}
+
+var (
+	prefixVarintTests = []prefixVarintTestCase{
+		{0, []byte{0x00}},
+		{1, []byte{0x01}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x80}},
+		{0x3fff, []byte{0xbf, 0xff}},
+		{0x4000, []byte{0xc0, 0x40, 0x00}},
+		{0xffffffff, []byte{0xf0, 0xff, 0xff, 0xff, 0xff}},
+		{1<<56 - 1, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{1 << 56, []byte{0xff, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{0xffffffffffffffff, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+)
+
+func TestPutPrefixVarint(t *testing.T) {
+	for _, tc := range prefixVarintTests {
+		buf := make([]byte, MaxPrefixVarintLen64)
+		n := PutPrefixVarint(buf, tc.value)
+		if !bytes.Equal(buf[:n], tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, buf[:n], tc)
+		}
+
+		value, read := PrefixVarint(tc.result)
+		if value != tc.value || read != len(tc.result) {
+			t.Errorf("Expected %d from %d bytes, got %d from %d for %v", tc.value, len(tc.result), value, read, tc)
+		}
+
+		if _, read := PrefixVarint(tc.result[:len(tc.result)-1]); read != 0 {
+			t.Errorf("Expected 0 bytes read from truncated data, got %d for %v", read, tc)
+		}
+	}
+}
+
+func TestPrefixVarint32(t *testing.T) {
+	buf := make([]byte, MaxPrefixVarintLen32)
+	for _, value := range []uint32{0, 1, 127, 128, 0x12345678, 0xffffffff} {
+		n := PutPrefixVarint32(buf, value)
+		result, read := PrefixVarint32(buf[:n])
+		if result != value || read != n {
+			t.Errorf("Expected %d from %d bytes, got %d from %d", value, n, result, read)
+		}
+	}
+
+	if _, read := PrefixVarint32([]byte{0xf8, 0x01, 0x00, 0x00, 0x00, 0x00}); read != -6 {
+		t.Errorf("Expected -6 for a value overflowing 32 bits, got %d", read)
+	}
+}
+
+// Encode the values as PrefixVarints, checking that they decode and that
+// none takes up more space than binary.PutUvarint would use.
+func encodePrefixVarint(t *testing.T, tc roundTripTestCase) uint {
+	buf := make([]byte, 512)
+	uvarintBuf := make([]byte, binary.MaxVarintLen64)
+
+	i := 0
+	for _, v := range tc.values {
+		written := PutPrefixVarint32(buf[i:], v)
+		if uvarintSize := binary.PutUvarint(uvarintBuf, uint64(v)); written > uvarintSize {
+			t.Errorf("Expected at most %d bytes, got %d for 0x%x", uvarintSize, written, v)
+		}
+		i += written
+	}
+
+	i = 0
+	for _, v := range tc.values {
+		actual, read := PrefixVarint32(buf[i:])
+		if read <= 0 {
+			t.Fatalf("Unexpected read of %d bytes for 0x%x", read, v)
+		}
+		if actual != v {
+			t.Errorf("Expected 0x%x, got 0x%x", v, actual)
+		}
+		i += read
+	}
+
+	return uint(i)
+}
+
+func TestPrefixVarintRandom(t *testing.T) {
+	for testCount := 0; testCount < 100000; testCount++ {
+		tc := roundTripTestCase{}
+		for i := 0; i < 10; i++ {
+			tc.values = append(tc.values, uint32(rand.Int63()&((1<<uint(rand.Int31n(33)))-1)))
+		}
+
+		// Both use 7 bits per byte for 32-bit values.
+		if size, expected := encodePrefixVarint(t, tc), encodeStandardVarint(tc); size != expected {
+			t.Fatalf("Expected %d bytes, got %d for %v", expected, size, tc)
+		}
+	}
+
+	buf := make([]byte, MaxPrefixVarintLen64)
+	uvarintBuf := make([]byte, binary.MaxVarintLen64)
+	for testCount := 0; testCount < 100000; testCount++ {
+		a := rand.Uint64() >> uint(rand.Int31n(64))
+		b := rand.Uint64() >> uint(rand.Int31n(64))
+
+		n := PutPrefixVarint(buf, a)
+		if uvarintSize := binary.PutUvarint(uvarintBuf, a); n > uvarintSize {
+			t.Fatalf("Expected at most %d bytes, got %d for 0x%x", uvarintSize, n, a)
+		}
+
+		encodedA := append([]byte{}, buf[:n]...)
+		encodedB := buf[:PutPrefixVarint(buf, b)]
+		if value, _ := PrefixVarint(encodedA); value != a {
+			t.Fatalf("Expected 0x%x, got 0x%x", a, value)
+		}
+
+		// Encoded values sort like the values themselves.
+		if (a < b) != (bytes.Compare(encodedA, encodedB) < 0) {
+			t.Fatalf("Mismatched order for 0x%x and 0x%x", a, b)
+		}
+	}
+}
+
+func BenchmarkPrefixVarint(b *testing.B) {
+	buf := make([]byte, MaxPrefixVarintLen64)
+	PutPrefixVarint(buf, 123456789)
+
+	for i := 0; i < b.N; i++ {
+		PrefixVarint(buf)
+	}
+}
+
+func BenchmarkUvarint(b *testing.B) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	binary.PutUvarint(buf, 123456789)
+
+	for i := 0; i < b.N; i++ {
+		binary.Uvarint(buf)
+	}
+}
@@ -0,0 +1,109 @@
+package govarint
+
+import (
+	"fmt"
+)
+
+// A Record reads single values of an encoded record without decoding the
+// others. Finding a value only walks the width prefixes before it.
+type Record struct {
+	fields     []uint8
+	prefixBits int
+	data       []byte
+}
+
+// Create a record view over data encoded with the given fields. The data is
+// not copied.
+func NewRecord(fields []uint8, data []byte) (*Record, error) {
+	if err := checkNotEmpty(fields, data); err != nil {
+		return nil, err
+	}
+
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	return newRecord(fields, prefixBitsOf(fields), data)
+}
+
+// Create a record view over data encoded with the schema.
+func (s *Schema) Record(data []byte) (*Record, error) {
+	if err := checkNotEmpty(s.fields, data); err != nil {
+		return nil, err
+	}
+
+	return newRecord(s.fields, s.prefixBits, data)
+}
+
+func newRecord(fields []uint8, prefixBits int, data []byte) (*Record, error) {
+	if totalBits := len(data) * 8; prefixBits > totalBits {
+		return nil, newError(ErrTruncated, -1, 0, totalBits, "ran out of data before end of width prefixes, expected %d bits of data, got %d", prefixBits, totalBits)
+	}
+
+	return &Record{fields: fields, prefixBits: prefixBits, data: data}, nil
+}
+
+// Return the number of fields in the record.
+func (r *Record) Len() int {
+	return len(r.fields)
+}
+
+// Return the value of field i.
+func (r *Record) Get(i int) (uint32, error) {
+	value, err := r.get(i, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	if r.fields[i]&SignedField != 0 {
+		return zigzagDecode32(uint32(value)), nil
+	}
+	return uint32(value), nil
+}
+
+// Return the value of field i as a 64-bit value.
+func (r *Record) Get64(i int) (uint64, error) {
+	value, err := r.get(i, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if r.fields[i]&SignedField != 0 {
+		return zigzagDecode64(value), nil
+	}
+	return value, nil
+}
+
+// Return the stored value of field i, before undoing the zigzag mapping of
+// signed fields.
+func (r *Record) get(i int, valueBits uint) (uint64, error) {
+	if i < 0 || i >= len(r.fields) {
+		return 0, fmt.Errorf("field index %d out of range for %d fields", i, len(r.fields))
+	}
+
+	// Skip the values before field i, whose widths are in the prefixes.
+	prefixPos := 0
+	valuePos := r.prefixBits
+	for _, fieldWidth := range r.fields[:i] {
+		fieldWidth &^= SignedField
+		if width := int(getBits(r.data, prefixPos, uint(fieldWidth))); width > 0 {
+			valuePos += width - 1
+		}
+		prefixPos += int(fieldWidth)
+	}
+
+	width := uint(getBits(r.data, prefixPos, uint(r.fields[i]&^SignedField)))
+	if width > valueBits {
+		return 0, newError(ErrInvalidWidth, i, uint64(width), prefixPos, "invalid value width %d for %d-bit values", width, valueBits)
+	}
+
+	if width == 0 {
+		return 0, nil
+	}
+
+	if totalBits := len(r.data) * 8; valuePos+int(width)-1 > totalBits {
+		return 0, newError(ErrTruncated, i, uint64(width), valuePos, "ran out of data before end of value, expected additional %d bits of data", valuePos+int(width)-1-totalBits)
+	}
+
+	return uint64(1)<<(width-1) | getBits(r.data, valuePos, width-1), nil
+}
@@ -0,0 +1,125 @@
+package govarint
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestRecordGet(t *testing.T) {
+	for _, tc := range encodeTests {
+		r, err := NewRecord(tc.fields, tc.result)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if r.Len() != len(tc.fields) {
+			t.Errorf("Expected length %d, got %d for %v", len(tc.fields), r.Len(), tc)
+		}
+
+		for i, expected := range tc.values {
+			value, err := r.Get(i)
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\" for field %d of %v", err, i, tc)
+			}
+			if value != expected {
+				t.Errorf("Expected %d, got %d for field %d of %v", expected, value, i, tc)
+			}
+		}
+	}
+}
+
+func TestRecordGet64Random(t *testing.T) {
+	for testCount := 0; testCount < 10000; testCount++ {
+		valueCount := int(rand.Int31n(30)) + 1
+
+		tc := roundTrip64TestCase{}
+		for i := 0; i < valueCount; i++ {
+			tc.values = append(tc.values, rand.Uint64()&((1<<uint(rand.Int31n(65)))-1))
+			if rand.Int31n(2) == 0 {
+				tc.fields = append(tc.fields, 7)
+			} else {
+				tc.fields = append(tc.fields, Signed(7))
+			}
+		}
+
+		data, err := Encode64(tc.fields, tc.values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, tc)
+		}
+
+		r, err := NewRecord(tc.fields, data)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, tc)
+		}
+
+		i := int(rand.Int31n(int32(valueCount)))
+		value, err := r.Get64(i)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for field %d of %v", err, i, tc)
+		}
+		if value != tc.values[i] {
+			t.Fatalf("Expected %d, got %d for field %d of %v", tc.values[i], value, i, tc)
+		}
+	}
+}
+
+func TestSchemaRecord(t *testing.T) {
+	schema, err := NewSchema(activityFields...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	data, _ := schema.Encode(activityValues)
+	r, err := schema.Record(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for i := r.Len() - 1; i >= 0; i-- {
+		if value, _ := r.Get(i); value != activityValues[i] {
+			t.Errorf("Expected %d, got %d for field %d", activityValues[i], value, i)
+		}
+	}
+}
+
+func TestRecordErrors(t *testing.T) {
+	if _, err := NewRecord([]uint8{3}, []byte{}); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+
+	if _, err := NewRecord([]uint8{6, 6}, []byte{0}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if _, err := NewRecord([]uint8{0}, []byte{0}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+
+	// Width 21 for the third field, with only 2 bytes of data.
+	r, err := NewRecord([]uint8{3, 3, 6}, []byte{0x2d, 0x54})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if value, err := r.Get(1); err != nil || value != 5 {
+		t.Errorf("Expected 5, got %d, %v", value, err)
+	}
+
+	if _, err := r.Get(2); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if _, err := r.Get(3); err == nil {
+		t.Errorf("Expected error for field index out of range")
+	}
+
+	r, _ = NewRecord([]uint8{6}, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	if _, err := r.Get(0); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+	if value, err := r.Get64(0); err != nil || value != 1<<63-1 {
+		t.Errorf("Expected %d, got %d, %v", uint64(1<<63-1), value, err)
+	}
+}
@@ -0,0 +1,179 @@
+package govarint
+
+import (
+	"fmt"
+)
+
+// A Schema is an ordered list of field widths that has been validated once
+// so that records can be encoded and decoded without checking the widths on
+// every call. A Schema is never modified after creation and can be shared
+// between goroutines.
+type Schema struct {
+	fields     []uint8
+	valueBits  int
+	prefixBits int
+	maxSize    int
+}
+
+// Create a schema for records of uint32 values. Widths above 6 bits are
+// rejected as they can describe values wider than 32 bits.
+func NewSchema(fields ...uint8) (*Schema, error) {
+	return newSchema(fields, 32)
+}
+
+// Create a schema for records of uint64 values. Widths above 7 bits are
+// rejected as they can describe values wider than 64 bits.
+func NewSchema64(fields ...uint8) (*Schema, error) {
+	return newSchema(fields, 64)
+}
+
+func newSchema(fields []uint8, valueBits int) (*Schema, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("schema requires at least one field")
+	}
+
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	// The narrowest width able to describe every value width.
+	maxFieldWidth := 32 - countLeadingZeros(uint32(valueBits))
+
+	for i, fieldWidth := range fields {
+		fieldWidth &^= SignedField
+		if int(fieldWidth) > maxFieldWidth {
+			return nil, newError(ErrInvalidWidth, i, uint64(fieldWidth), -1, "field width %d too large for %d-bit values", fieldWidth, valueBits)
+		}
+	}
+
+	s := &Schema{
+		fields:     make([]uint8, len(fields)),
+		valueBits:  valueBits,
+		prefixBits: prefixBitsOf(fields),
+		maxSize:    (maxEncodedBits(fields, valueBits) + 7) / 8,
+	}
+	copy(s.fields, fields)
+
+	return s, nil
+}
+
+// Return a copy of the field widths of the schema.
+func (s *Schema) Fields() []uint8 {
+	fields := make([]uint8, len(s.fields))
+	copy(fields, s.fields)
+	return fields
+}
+
+// Return the number of fields in the schema.
+func (s *Schema) Len() int {
+	return len(s.fields)
+}
+
+// Return the largest number of bytes a single record can be encoded to.
+func (s *Schema) MaxSize() int {
+	return s.maxSize
+}
+
+// Encode the given values, see Encode.
+func (s *Schema) Encode(values []uint32) ([]byte, error) {
+	if err := s.check(32, len(values)); err != nil {
+		return []byte{}, err
+	}
+
+	return encode(s.fields, values)
+}
+
+// Decode a record, see Decode.
+func (s *Schema) Decode(data []byte) ([]uint32, error) {
+	if err := s.check(32, len(s.fields)); err != nil {
+		return []uint32{}, err
+	}
+
+	return Decode(s.fields, data)
+}
+
+// Encode the given values, see Encode64.
+func (s *Schema) Encode64(values []uint64) ([]byte, error) {
+	if err := s.check(64, len(values)); err != nil {
+		return []byte{}, err
+	}
+
+	return encode64(s.fields, values)
+}
+
+// Decode a record, see Decode64.
+func (s *Schema) Decode64(data []byte) ([]uint64, error) {
+	if err := s.check(64, len(s.fields)); err != nil {
+		return []uint64{}, err
+	}
+
+	return Decode64(s.fields, data)
+}
+
+// Decode the record at the start of data, see DecodeN.
+func (s *Schema) DecodeN(data []byte) ([]uint32, int, error) {
+	if err := s.check(32, len(s.fields)); err != nil {
+		return []uint32{}, 0, err
+	}
+
+	values := make([]uint32, len(s.fields))
+	_, bits, err := decodeInto(values, s.fields, s.prefixBits, data)
+	if err != nil {
+		return []uint32{}, 0, err
+	}
+
+	return values, (bits + 7) / 8, nil
+}
+
+// Decode the 64-bit record at the start of data, see DecodeN64.
+func (s *Schema) DecodeN64(data []byte) ([]uint64, int, error) {
+	if err := s.check(64, len(s.fields)); err != nil {
+		return []uint64{}, 0, err
+	}
+
+	values := make([]uint64, len(s.fields))
+	_, bits, err := decodeInto64(values, s.fields, s.prefixBits, data)
+	if err != nil {
+		return []uint64{}, 0, err
+	}
+
+	return values, (bits + 7) / 8, nil
+}
+
+func (s *Schema) check(valueBits int, valueCount int) error {
+	if s.valueBits > valueBits {
+		return fmt.Errorf("schema holds %d-bit values, got %d-bit values", s.valueBits, valueBits)
+	}
+
+	if valueCount != len(s.fields) {
+		return countMismatchError(len(s.fields), valueCount)
+	}
+
+	return nil
+}
+
+// Return the total number of bits of the record starting with the given
+// width prefixes, which must hold at least prefixBits bits.
+func (s *Schema) recordBits(prefix []byte) (int, error) {
+	data, curByte, curIndex := cursorAt(prefix, 0)
+
+	prefixPos := 0
+	totalBits := s.prefixBits
+	for i, fieldWidth := range s.fields {
+		width, err := popBitsFromSlice(&data, fieldWidth&^SignedField, &curByte, &curIndex, false)
+		if err != nil {
+			return 0, err
+		}
+
+		if int(width) > s.valueBits {
+			return 0, newError(ErrInvalidWidth, i, uint64(width), prefixPos, "invalid value width %d for %d-bit values", width, s.valueBits)
+		}
+		prefixPos += int(fieldWidth &^ SignedField)
+
+		if width > 0 {
+			totalBits += int(width) - 1
+		}
+	}
+
+	return totalBits, nil
+}
@@ -0,0 +1,225 @@
+package govarint
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+type schemaTestCase struct {
+	fields  []uint8
+	is64    bool
+	maxSize int
+	err     string
+}
+
+var (
+	schemaTests = []schemaTestCase{
+		{[]uint8{1}, false, 1, ""},
+		{[]uint8{6}, false, 5, ""},
+		{[]uint8{6, 1}, false, 5, ""},
+		{[]uint8{7}, true, 9, ""},
+		{[]uint8{3, 3, 6, 3, 6}, false, 13, ""},
+		{[]uint8{Signed(6)}, false, 5, ""},
+
+		{[]uint8{}, false, 0, "schema requires at least one field"},
+		{[]uint8{3, 0}, false, 0, "received invalid 0 field width"},
+		{[]uint8{Signed(0)}, false, 0, "received invalid 0 field width"},
+		{[]uint8{7}, false, 0, "field width 7 too large for 32-bit values"},
+		{[]uint8{8}, true, 0, "field width 8 too large for 64-bit values"},
+	}
+)
+
+func TestNewSchema(t *testing.T) {
+	for _, tc := range schemaTests {
+		var s *Schema
+		var err error
+		if tc.is64 {
+			s, err = NewSchema64(tc.fields...)
+		} else {
+			s, err = NewSchema(tc.fields...)
+		}
+
+		if tc.err != "" {
+			if err == nil {
+				t.Errorf("Expected error \"%s\", received no error for %v", tc.err, tc)
+			} else if err.Error() != tc.err {
+				t.Errorf("Expected error \"%s\", got: %s for %v", tc.err, err, tc)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if s.MaxSize() != tc.maxSize {
+			t.Errorf("Expected max size %d, got %d for %v", tc.maxSize, s.MaxSize(), tc)
+		}
+		if s.Len() != len(tc.fields) {
+			t.Errorf("Expected %d fields, got %d for %v", len(tc.fields), s.Len(), tc)
+		}
+	}
+}
+
+func TestSchemaMaxSize(t *testing.T) {
+	s, err := NewSchema(6, 6)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	data, err := s.Encode([]uint32{1<<32 - 1, 1<<32 - 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(data) != s.MaxSize() {
+		t.Errorf("Expected %d bytes, got %d", s.MaxSize(), len(data))
+	}
+}
+
+func TestSchemaMatchesEncode(t *testing.T) {
+	for _, tc := range encodeTests {
+		// Encode accepts field widths a schema rejects.
+		oversize := false
+		for _, fieldWidth := range tc.fields {
+			if fieldWidth&^SignedField > 6 {
+				oversize = true
+			}
+		}
+		if oversize {
+			continue
+		}
+
+		s, err := NewSchema(tc.fields...)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		result, err := s.Encode(tc.values)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+			continue
+		}
+
+		values, err := s.Decode(result)
+		if err != nil {
+			t.Errorf("Unexpected decode error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		for i, expected := range tc.values {
+			if expected != values[i] {
+				t.Errorf("Incorrect value, expected 0x%08x, got 0x%08x for %v", expected, values[i], tc)
+			}
+		}
+	}
+}
+
+func TestSchemaValueSize(t *testing.T) {
+	s, err := NewSchema64(3, 7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, err := s.Encode([]uint32{1, 2}); err == nil {
+		t.Errorf("Expected error encoding 32-bit values with a 64-bit schema")
+	}
+
+	data, err := s.Encode64([]uint64{5, 1 << 40})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	values, err := s.Decode64(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if values[0] != 5 || values[1] != 1<<40 {
+		t.Errorf("Incorrect values %v", values)
+	}
+
+	if _, err := s.Encode64([]uint64{5}); err == nil {
+		t.Errorf("Expected error for mismatched value count")
+	}
+}
+
+func TestSchemaConcurrentUse(t *testing.T) {
+	s, err := NewSchema(3, 3, 6, 3, 6)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i uint32) {
+			defer wg.Done()
+
+			for j := uint32(0); j < 1000; j++ {
+				values := []uint32{i, 5, j * 1000, 2, i * j}
+				data, err := s.Encode(values)
+				if err != nil {
+					t.Error(err.Error())
+					return
+				}
+
+				result, err := s.Decode(data)
+				if err != nil {
+					t.Error(err.Error())
+					return
+				}
+
+				for k := range values {
+					if values[k] != result[k] {
+						t.Errorf("Incorrect value, expected %d, got %d", values[k], result[k])
+						return
+					}
+				}
+			}
+		}(uint32(i))
+	}
+	wg.Wait()
+}
+
+func TestSchemaDecodeN(t *testing.T) {
+	s, err := NewSchema(4, Signed(5))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	data := []byte{}
+	records := [][]uint32{{1, 12345}, {0, 0}, {15, uint32(0xfffffff0)}}
+	for _, values := range records {
+		data, err = s.AppendEncode(data, values)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	for _, expected := range records {
+		values, n, err := s.DecodeN(data)
+		if err != nil {
+			t.Fatalf("Unexpected decode error: %s", err)
+		}
+
+		for i := range expected {
+			if expected[i] != values[i] {
+				t.Errorf("Incorrect value, expected 0x%08x, got 0x%08x", expected[i], values[i])
+			}
+		}
+
+		data = data[n:]
+	}
+
+	if len(data) != 0 {
+		t.Errorf("Expected all data to be consumed, 0x%x left", data)
+	}
+}
@@ -0,0 +1,36 @@
+package govarint
+
+// SignedField marks a field width as holding a signed value. Widths never
+// need the top bit, so it is free to carry the flag, e.g. a field list of
+// {3, Signed(6)} describes an unsigned 3-bit field followed by a signed
+// 6-bit field.
+//
+// Values of signed fields are passed to Encode as the two's complement bit
+// pattern of the signed value (uint32(int32(v)), or uint64(int64(v)) for
+// Encode64) and returned by Decode in the same form. They are zigzag mapped
+// on the wire so that values of small magnitude stay small regardless of
+// their sign.
+const SignedField uint8 = 0x80
+
+// Return the given field width marked as signed.
+func Signed(width uint8) uint8 {
+	return width | SignedField
+}
+
+// Map signed values onto unsigned ones so that 0, -1, 1, -2, ... become
+// 0, 1, 2, 3, ...
+func zigzagEncode32(x uint32) uint32 {
+	return (x << 1) ^ uint32(int32(x)>>31)
+}
+
+func zigzagDecode32(x uint32) uint32 {
+	return (x >> 1) ^ -(x & 1)
+}
+
+func zigzagEncode64(x uint64) uint64 {
+	return (x << 1) ^ uint64(int64(x)>>63)
+}
+
+func zigzagDecode64(x uint64) uint64 {
+	return (x >> 1) ^ -(x & 1)
+}
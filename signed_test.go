@@ -0,0 +1,143 @@
+package govarint
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+type zigzagTestCase struct {
+	value  int32
+	mapped uint32
+}
+
+type signedEncodeTestCase struct {
+	fields []uint8
+	values []int32
+	result []byte
+}
+
+var (
+	zigzagTests = []zigzagTestCase{
+		{0, 0},
+		{-1, 1},
+		{1, 2},
+		{-2, 3},
+		{2, 4},
+		{2147483647, 4294967294},
+		{-2147483648, 4294967295},
+	}
+
+	signedEncodeTests = []signedEncodeTestCase{
+		// Signed zero is still only the field specifier.
+		{[]uint8{Signed(3)}, []int32{0}, []byte{0}},
+
+		// -1 maps to 1, which takes up only the field specifier.
+		{[]uint8{Signed(2)}, []int32{-1}, []byte{0x40}},
+		{[]uint8{2}, []int32{1}, []byte{0x40}},
+
+		// 1 maps to 2, same as the unsigned encoding of 2.
+		{[]uint8{Signed(3), 1}, []int32{1, 1}, []byte{0x50}},
+		{[]uint8{3, 1}, []int32{2, 1}, []byte{0x50}},
+
+		{[]uint8{3, Signed(3)}, []int32{0, -3}, []byte{0x0d}},
+	}
+)
+
+func TestZigzag(t *testing.T) {
+	for _, tc := range zigzagTests {
+		mapped := zigzagEncode32(uint32(tc.value))
+		if mapped != tc.mapped {
+			t.Errorf("Expected %d, got %d for %d", tc.mapped, mapped, tc.value)
+		}
+
+		value := int32(zigzagDecode32(mapped))
+		if value != tc.value {
+			t.Errorf("Expected %d, got %d for %d", tc.value, value, tc.mapped)
+		}
+
+		mapped64 := zigzagEncode64(uint64(int64(tc.value)))
+		if mapped64 != uint64(tc.mapped) {
+			t.Errorf("Expected %d, got %d for %d", tc.mapped, mapped64, tc.value)
+		}
+
+		value64 := int64(zigzagDecode64(mapped64))
+		if value64 != int64(tc.value) {
+			t.Errorf("Expected %d, got %d for %d", tc.value, value64, tc.mapped)
+		}
+	}
+}
+
+func TestSignedEncode(t *testing.T) {
+	for _, tc := range signedEncodeTests {
+		values := make([]uint32, 0, len(tc.values))
+		for _, v := range tc.values {
+			values = append(values, uint32(v))
+		}
+
+		result, err := Encode(tc.fields, values)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+			continue
+		}
+
+		decoded, err := Decode(tc.fields, result)
+		if err != nil {
+			t.Errorf("Unexpected decode error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		for i, expected := range tc.values {
+			if expected != int32(decoded[i]) {
+				t.Errorf("Incorrect value, expected %d, got %d for %v", expected, int32(decoded[i]), tc)
+			}
+		}
+	}
+}
+
+func TestSignedRoundTrips(t *testing.T) {
+	for testCount := 0; testCount < 100000; testCount++ {
+		valueCount := int(rand.Int31n(30)) + 1
+
+		values := []uint32{}
+		values64 := []uint64{}
+		fields := []uint8{}
+		for i := 0; i < valueCount; i++ {
+			curValue := int32(rand.Int63() & ((1 << uint(rand.Int31n(32))) - 1))
+			if rand.Int31n(2) == 0 {
+				curValue = -curValue - 1
+			}
+			values = append(values, uint32(curValue))
+			values64 = append(values64, uint64(int64(curValue)))
+			fields = append(fields, Signed(6))
+		}
+
+		_, err := executeRoundTrip(roundTripTestCase{fields, values})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		_, err = executeRoundTrip64(roundTrip64TestCase{fields, values64})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+}
+
+func TestSignedValueTooLarge(t *testing.T) {
+	// -4 maps to 7, which needs three bits.
+	_, err := Encode([]uint8{Signed(1)}, []uint32{uint32(0xfffffffc)})
+	if err == nil {
+		t.Errorf("Did not receive expected error")
+	}
+
+	_, err = Encode([]uint8{Signed(2)}, []uint32{uint32(0xfffffffc)})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,134 @@
+package govarint
+
+// Return the number of bytes Encode would produce for the given values,
+// without encoding them.
+func EncodedSize(fields []uint8, values []uint32) (int, error) {
+	if len(fields) != len(values) {
+		return 0, countMismatchError(len(fields), len(values))
+	}
+
+	if err := validateFields(fields); err != nil {
+		return 0, err
+	}
+
+	prefixBits, valueBits, err := encodedBits(fields, values)
+	return (prefixBits + valueBits + 7) / 8, err
+}
+
+// Return the number of bytes Encode64 would produce for the given values.
+func EncodedSize64(fields []uint8, values []uint64) (int, error) {
+	if len(fields) != len(values) {
+		return 0, countMismatchError(len(fields), len(values))
+	}
+
+	if err := validateFields(fields); err != nil {
+		return 0, err
+	}
+
+	prefixBits, valueBits, err := encodedBits64(fields, values)
+	return (prefixBits + valueBits + 7) / 8, err
+}
+
+// Return the largest number of bytes Encode can produce for the given
+// fields.
+func MaxEncodedSize(fields []uint8) int {
+	return (maxEncodedBits(fields, 32) + 7) / 8
+}
+
+// Return the largest number of bytes Encode64 can produce for the given
+// fields.
+func MaxEncodedSize64(fields []uint8) int {
+	return (maxEncodedBits(fields, 64) + 7) / 8
+}
+
+// Return the number of bytes the given values would be encoded to.
+func (s *Schema) EncodedSize(values []uint32) (int, error) {
+	if err := s.check(32, len(values)); err != nil {
+		return 0, err
+	}
+
+	prefixBits, valueBits, err := encodedBits(s.fields, values)
+	return (prefixBits + valueBits + 7) / 8, err
+}
+
+// Return the number of bytes the given 64-bit values would be encoded to.
+func (s *Schema) EncodedSize64(values []uint64) (int, error) {
+	if err := s.check(64, len(values)); err != nil {
+		return 0, err
+	}
+
+	prefixBits, valueBits, err := encodedBits64(s.fields, values)
+	return (prefixBits + valueBits + 7) / 8, err
+}
+
+// Return the number of bits taken up by the width prefixes and by the
+// values, leaving out the implied leading bit of each value.
+func encodedBits(fields []uint8, values []uint32) (int, int, error) {
+	prefixBits := 0
+	valueBits := 0
+	for i, fieldWidth := range fields {
+		value := values[i]
+		if fieldWidth&SignedField != 0 {
+			fieldWidth &^= SignedField
+			value = zigzagEncode32(value)
+		}
+
+		valueWidth := 32 - countLeadingZeros(value)
+		if valueWidth > (1<<fieldWidth)-1 {
+			return 0, 0, newError(ErrValueTooLarge, i, uint64(values[i]), -1, "value %d too large for field width %d", values[i], fieldWidth)
+		}
+
+		prefixBits += int(fieldWidth)
+		if valueWidth > 0 {
+			valueBits += valueWidth - 1
+		}
+	}
+
+	return prefixBits, valueBits, nil
+}
+
+func encodedBits64(fields []uint8, values []uint64) (int, int, error) {
+	prefixBits := 0
+	valueBits := 0
+	for i, fieldWidth := range fields {
+		value := values[i]
+		if fieldWidth&SignedField != 0 {
+			fieldWidth &^= SignedField
+			value = zigzagEncode64(value)
+		}
+
+		valueWidth := 64 - countLeadingZeros64(value)
+		if valueWidth > (1<<fieldWidth)-1 {
+			return 0, 0, newError(ErrValueTooLarge, i, values[i], -1, "value %d too large for field width %d", values[i], fieldWidth)
+		}
+
+		prefixBits += int(fieldWidth)
+		if valueWidth > 0 {
+			valueBits += valueWidth - 1
+		}
+	}
+
+	return prefixBits, valueBits, nil
+}
+
+// Return the largest number of bits a record of the given fields can take
+// up when holding values of at most valueBits bits.
+func maxEncodedBits(fields []uint8, valueBits int) int {
+	totalBits := 0
+	for _, fieldWidth := range fields {
+		fieldWidth &^= SignedField
+
+		maxValueWidth := valueBits
+		if fieldWidth < 8 && (1<<fieldWidth)-1 < valueBits {
+			maxValueWidth = (1 << fieldWidth) - 1
+		}
+
+		// The leading bit of each value is implied by its width.
+		totalBits += int(fieldWidth)
+		if maxValueWidth > 0 {
+			totalBits += maxValueWidth - 1
+		}
+	}
+
+	return totalBits
+}
@@ -0,0 +1,117 @@
+package govarint
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+type maxSizeTestCase struct {
+	fields []uint8
+	size   int
+	size64 int
+}
+
+var (
+	maxSizeTests = []maxSizeTestCase{
+		{[]uint8{1}, 1, 1},
+		{[]uint8{3}, 2, 2},
+		{[]uint8{6}, 5, 9},
+		{[]uint8{7}, 5, 9},
+		{[]uint8{Signed(6), 1}, 5, 9},
+		{[]uint8{3, 3, 6, 3, 6}, 13, 21},
+	}
+)
+
+func TestEncodedSize(t *testing.T) {
+	for _, tc := range encodeTests {
+		size, err := EncodedSize(tc.fields, tc.values)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if size != len(tc.result) {
+			t.Errorf("Expected size %d, got %d for %v", len(tc.result), size, tc)
+		}
+	}
+}
+
+func TestEncodedSizeRandom(t *testing.T) {
+	for testCount := 0; testCount < 100000; testCount++ {
+		valueCount := int(rand.Int31n(30)) + 1
+
+		tc := roundTrip64TestCase{}
+		for i := 0; i < valueCount; i++ {
+			tc.values = append(tc.values, rand.Uint64()&((1<<uint(rand.Int31n(65)))-1))
+			tc.fields = append(tc.fields, 7)
+		}
+
+		data, err := Encode64(tc.fields, tc.values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, tc)
+		}
+
+		size, err := EncodedSize64(tc.fields, tc.values)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, tc)
+		}
+
+		if size != len(data) {
+			t.Fatalf("Expected size %d, got %d for %v", len(data), size, tc)
+		}
+
+		if size > MaxEncodedSize64(tc.fields) {
+			t.Fatalf("Size %d exceeds maximum size %d for %v", size, MaxEncodedSize64(tc.fields), tc)
+		}
+	}
+}
+
+func TestEncodedSizeErrors(t *testing.T) {
+	if _, err := EncodedSize([]uint8{2}, []uint32{8}); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge, got %v", err)
+	}
+
+	if _, err := EncodedSize([]uint8{2}, []uint32{}); !errors.Is(err, ErrFieldCountMismatch) {
+		t.Errorf("Expected ErrFieldCountMismatch, got %v", err)
+	}
+
+	if _, err := EncodedSize64([]uint8{0}, []uint64{0}); !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+}
+
+func TestMaxEncodedSize(t *testing.T) {
+	for _, tc := range maxSizeTests {
+		if size := MaxEncodedSize(tc.fields); size != tc.size {
+			t.Errorf("Expected max size %d, got %d for %v", tc.size, size, tc)
+		}
+
+		if size := MaxEncodedSize64(tc.fields); size != tc.size64 {
+			t.Errorf("Expected 64-bit max size %d, got %d for %v", tc.size64, size, tc)
+		}
+	}
+}
+
+func TestSchemaEncodedSize(t *testing.T) {
+	s, err := NewSchema(3, 3, 6, 3, 6)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	values := []uint32{1, 5, 1128411, 2, 123456789}
+	data, _ := s.Encode(values)
+
+	size, err := s.EncodedSize(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if size != len(data) {
+		t.Errorf("Expected size %d, got %d", len(data), size)
+	}
+
+	if s.MaxSize() != MaxEncodedSize(s.Fields()) {
+		t.Errorf("Expected schema max size %d, got %d", MaxEncodedSize(s.Fields()), s.MaxSize())
+	}
+}
@@ -0,0 +1,110 @@
+package govarint
+
+import (
+	"bufio"
+	"io"
+)
+
+// An Encoder writes successive records of a schema to a stream.
+//
+// Records need no framing as their length follows from their width
+// prefixes. Writes are buffered, so Flush must be called once the last
+// record has been encoded.
+type Encoder struct {
+	w      *bufio.Writer
+	schema *Schema
+}
+
+// Create an encoder writing records of the given schema to w.
+func NewEncoder(w io.Writer, schema *Schema) *Encoder {
+	return &Encoder{bufio.NewWriter(w), schema}
+}
+
+// Write a record holding the given values.
+func (e *Encoder) Encode(values []uint32) error {
+	data, err := e.schema.Encode(values)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+// Write a record holding the given 64-bit values.
+func (e *Encoder) Encode64(values []uint64) error {
+	data, err := e.schema.Encode64(values)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+// Write any buffered records to the underlying writer.
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+// A Decoder reads successive records of a schema from a stream.
+type Decoder struct {
+	r      *bufio.Reader
+	schema *Schema
+	buf    []byte
+}
+
+// Create a decoder reading records of the given schema from r.
+func NewDecoder(r io.Reader, schema *Schema) *Decoder {
+	return &Decoder{
+		r:      bufio.NewReader(r),
+		schema: schema,
+		buf:    make([]byte, schema.MaxSize()),
+	}
+}
+
+// Read the next record. Returns io.EOF if the stream ends before the
+// record and io.ErrUnexpectedEOF if it ends part way through it.
+func (d *Decoder) Decode() ([]uint32, error) {
+	record, err := d.next()
+	if err != nil {
+		return []uint32{}, err
+	}
+
+	return d.schema.Decode(record)
+}
+
+// Read the next record of 64-bit values, see Decode.
+func (d *Decoder) Decode64() ([]uint64, error) {
+	record, err := d.next()
+	if err != nil {
+		return []uint64{}, err
+	}
+
+	return d.schema.Decode64(record)
+}
+
+// Read the bytes of the next record, first reading its width prefixes to
+// find out how long it is.
+func (d *Decoder) next() ([]byte, error) {
+	prefixSize := (d.schema.prefixBits + 7) / 8
+
+	if _, err := io.ReadFull(d.r, d.buf[:prefixSize]); err != nil {
+		return nil, err
+	}
+
+	totalBits, err := d.schema.recordBits(d.buf[:prefixSize])
+	if err != nil {
+		return nil, err
+	}
+
+	size := (totalBits + 7) / 8
+	if _, err := io.ReadFull(d.r, d.buf[prefixSize:size]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return d.buf[:size], nil
+}
@@ -0,0 +1,129 @@
+package govarint
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStreamRoundTrip(t *testing.T) {
+	schema, err := NewSchema(3, 3, 6, 3, 6)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	records := [][]uint32{}
+	for i := 0; i < 10000; i++ {
+		records = append(records, []uint32{
+			uint32(rand.Int31n(8)),
+			uint32(rand.Int31n(8)),
+			rand.Uint32() >> uint(rand.Int31n(33)),
+			0,
+			uint32(rand.Int31n(100000000)),
+		})
+	}
+
+	buf := &bytes.Buffer{}
+	enc := NewEncoder(buf, schema)
+	for _, values := range records {
+		if err := enc.Encode(values); err != nil {
+			t.Fatalf("Unexpected encode error: %s", err)
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Unexpected flush error: %s", err)
+	}
+
+	dec := NewDecoder(iotest.OneByteReader(buf), schema)
+	for _, expected := range records {
+		values, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Unexpected decode error: %s", err)
+		}
+
+		for i := range expected {
+			if expected[i] != values[i] {
+				t.Fatalf("Incorrect value, expected 0x%08x, got 0x%08x for %v", expected[i], values[i], expected)
+			}
+		}
+	}
+
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestStreamRoundTrip64(t *testing.T) {
+	schema, err := NewSchema64(3, Signed(7), 7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	records := [][]uint64{
+		{0, 0, 0},
+		{7, uint64(1<<63 - 1), 1<<64 - 1},
+		{1, ^uint64(0), 1 << 40},
+	}
+
+	buf := &bytes.Buffer{}
+	enc := NewEncoder(buf, schema)
+	for _, values := range records {
+		if err := enc.Encode64(values); err != nil {
+			t.Fatalf("Unexpected encode error: %s", err)
+		}
+	}
+	enc.Flush()
+
+	dec := NewDecoder(buf, schema)
+	for _, expected := range records {
+		values, err := dec.Decode64()
+		if err != nil {
+			t.Fatalf("Unexpected decode error: %s", err)
+		}
+
+		for i := range expected {
+			if expected[i] != values[i] {
+				t.Fatalf("Incorrect value, expected 0x%016x, got 0x%016x for %v", expected[i], values[i], expected)
+			}
+		}
+	}
+
+	if _, err := dec.Decode64(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestStreamTruncated(t *testing.T) {
+	schema, err := NewSchema(4, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// A record of {1, 12345} is 0x1740e4, cut short after each byte.
+	for _, data := range [][]byte{{0x17}, {0x17, 0x40}} {
+		dec := NewDecoder(bytes.NewReader(data), schema)
+		if _, err := dec.Decode(); err != io.ErrUnexpectedEOF {
+			t.Errorf("Expected io.ErrUnexpectedEOF, got %v for 0x%x", err, data)
+		}
+	}
+
+	dec := NewDecoder(bytes.NewReader([]byte{0x17, 0x40, 0xe4}), schema)
+	if values, err := dec.Decode(); err != nil || values[0] != 1 || values[1] != 12345 {
+		t.Errorf("Expected [1 12345], got %v, %v", values, err)
+	}
+}
+
+func TestStreamInvalidWidth(t *testing.T) {
+	schema, err := NewSchema(6)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// A width prefix of 63 can not describe a 32-bit value.
+	dec := NewDecoder(bytes.NewReader([]byte{0xfc, 0, 0, 0, 0, 0, 0, 0, 0}), schema)
+	if _, err := dec.Decode(); err == nil {
+		t.Errorf("Expected error for invalid width")
+	}
+}
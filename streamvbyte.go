@@ -0,0 +1,104 @@
+package govarint
+
+import (
+	"encoding/binary"
+)
+
+// Byte lengths of the four values described by a Stream VByte control byte,
+// and their total.
+type streamVByteControl struct {
+	lengths [4]uint8
+	total   int
+}
+
+// Lengths described by each possible control byte, so the decoder can look
+// up a whole group at once.
+var streamVByteTable = func() (table [256]streamVByteControl) {
+	for control := range table {
+		for j := 0; j < 4; j++ {
+			length := uint8(control>>(2*uint(j))&0x3) + 1
+			table[control].lengths[j] = length
+			table[control].total += int(length)
+		}
+	}
+	return table
+}()
+
+// Encode values in Stream VByte format. Like Group Varint, every value takes
+// up one to four bytes, least significant byte first, with its length less
+// one given by two bits of a control byte per group of four values. Unlike
+// Group Varint, all control bytes are written first and the value bytes
+// after them, so a decoder can work through the control bytes without
+// waiting on the values. The first value of a group is described by the low
+// bits of its control byte, and unused bits of the last control byte are
+// zero.
+func EncodeStreamVByte(values []uint32) []byte {
+	controlLen := (len(values) + 3) / 4
+	data := make([]byte, controlLen, controlLen+len(values)*4)
+
+	for i, value := range values {
+		length := 1
+		for length < 4 && value>>(8*uint(length)) != 0 {
+			length++
+		}
+
+		data[i/4] |= uint8(length-1) << (2 * uint(i%4))
+		for k := 0; k < length; k++ {
+			data = append(data, uint8(value>>(8*uint(k))))
+		}
+	}
+
+	return data
+}
+
+// Decode count values encoded with EncodeStreamVByte.
+func DecodeStreamVByte(count int, data []byte) ([]uint32, error) {
+	if count < 0 {
+		return []uint32{}, newError(ErrFieldCountMismatch, -1, 0, -1, "invalid value count %d", count)
+	}
+
+	// Every value takes up at least one byte besides the control bytes.
+	controlLen := (count + 3) / 4
+	if controlLen+count > len(data) {
+		return []uint32{}, newError(ErrTruncated, -1, 0, len(data)*8, "ran out of data, expected at least %d bytes for %d values, got %d", controlLen+count, count, len(data))
+	}
+
+	values := make([]uint32, count)
+	control := data[:controlLen]
+	offset := controlLen
+	for i := 0; i < count; i += 4 {
+		c := &streamVByteTable[control[i/4]]
+
+		// The last group may describe fewer than four values.
+		n := 4
+		total := c.total
+		if count-i < 4 {
+			n = count - i
+			total = 0
+			for _, length := range c.lengths[:n] {
+				total += int(length)
+			}
+		}
+
+		if offset+total > len(data) {
+			return []uint32{}, newError(ErrTruncated, i, 0, offset*8, "ran out of data before end of value, expected %d bytes, got %d", total, len(data)-offset)
+		}
+
+		for j, length := range c.lengths[:n] {
+			b := data[offset:]
+			switch length {
+			case 1:
+				values[i+j] = uint32(b[0])
+			case 2:
+				values[i+j] = uint32(binary.LittleEndian.Uint16(b))
+			case 3:
+				values[i+j] = uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+			case 4:
+				values[i+j] = binary.LittleEndian.Uint32(b)
+			}
+			offset += int(length)
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,110 @@
+package govarint
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type streamVByteTestCase struct {
+	values []uint32
+	result []byte
+}
+
+var (
+	streamVByteTests = []streamVByteTestCase{
+		{[]uint32{}, []byte{}},
+		{[]uint32{0}, []byte{0x00, 0x00}},
+		{[]uint32{1, 256, 65536, 16777216}, []byte{0xe4, 0x01, 0x00, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01}},
+		{[]uint32{0xffffffff, 0, 0, 0, 0x1234}, []byte{0x03, 0x01, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x34, 0x12}},
+	}
+)
+
+func TestEncodeStreamVByte(t *testing.T) {
+	for _, tc := range streamVByteTests {
+		result := EncodeStreamVByte(tc.values)
+		if !bytes.Equal(result, tc.result) {
+			t.Errorf("Expected 0x%x, got 0x%x for %v", tc.result, result, tc)
+		}
+
+		values, err := DecodeStreamVByte(len(tc.values), result)
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("Expected %v, got %v for %v", tc.values, values, tc)
+		}
+	}
+}
+
+func TestStreamVByteRoundTrip(t *testing.T) {
+	for testCount := 0; testCount < 10000; testCount++ {
+		values := make([]uint32, rand.Int31n(300))
+		for i := range values {
+			values[i] = uint32(rand.Int63() & ((1 << uint(rand.Int31n(33))) - 1))
+		}
+
+		data := EncodeStreamVByte(values)
+		result, err := DecodeStreamVByte(len(values), data)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %v", err, values)
+		}
+
+		if !reflect.DeepEqual(result, values) {
+			t.Fatalf("Expected %v, got %v", values, result)
+		}
+
+		// Stream VByte and Group Varint take up the same space.
+		if groupSize := len(EncodeGroupVarint(values)); len(data) != groupSize {
+			t.Fatalf("Expected %d bytes, got %d for %v", groupSize, len(data), values)
+		}
+	}
+}
+
+func TestDecodeStreamVByteErrors(t *testing.T) {
+	if _, err := DecodeStreamVByte(-1, []byte{}); !errors.Is(err, ErrFieldCountMismatch) {
+		t.Errorf("Expected ErrFieldCountMismatch, got %v", err)
+	}
+
+	if _, err := DecodeStreamVByte(2, []byte{0x00, 0x01}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if _, err := DecodeStreamVByte(2, []byte{0x0c, 0x01, 0x02}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	if _, err := DecodeStreamVByte(4, []byte{0xff, 0x01, 0x02, 0x03, 0x04, 0x05}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+}
+
+func BenchmarkDecodeStreamVByte(b *testing.B) {
+	values := make([]uint32, 1024)
+	for i := range values {
+		values[i] = uint32(rand.Int63() & ((1 << uint(rand.Int31n(33))) - 1))
+	}
+	data := EncodeStreamVByte(values)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DecodeStreamVByte(len(values), data)
+	}
+}
+
+func BenchmarkDecodeGroupVarint(b *testing.B) {
+	values := make([]uint32, 1024)
+	for i := range values {
+		values[i] = uint32(rand.Int63() & ((1 << uint(rand.Int31n(33))) - 1))
+	}
+	data := EncodeGroupVarint(values)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DecodeGroupVarint(len(values), data)
+	}
+}
@@ -0,0 +1,88 @@
+package govarint
+
+// Decode a record like Decode does, but reject anything that Encode could
+// not have produced. This is meant for data from untrusted sources such as
+// clients and caches. Errors wrap ErrEmpty, ErrInvalidWidth, ErrTruncated,
+// ErrNonZeroPadding or ErrTrailingData.
+func DecodeStrict(fields []uint8, data []byte) ([]uint32, error) {
+	values := make([]uint32, len(fields))
+
+	if err := checkNotEmpty(fields, data); err != nil {
+		return []uint32{}, err
+	}
+
+	_, bits, err := decodeInto(values, fields, prefixBitsOf(fields), data)
+	if err != nil {
+		return []uint32{}, err
+	}
+
+	if err := checkRecordEnd(data, bits); err != nil {
+		return []uint32{}, err
+	}
+
+	return values, nil
+}
+
+// Decode a record of 64-bit values, see DecodeStrict.
+func DecodeStrict64(fields []uint8, data []byte) ([]uint64, error) {
+	values := make([]uint64, len(fields))
+
+	if err := checkNotEmpty(fields, data); err != nil {
+		return []uint64{}, err
+	}
+
+	_, bits, err := decodeInto64(values, fields, prefixBitsOf(fields), data)
+	if err != nil {
+		return []uint64{}, err
+	}
+
+	if err := checkRecordEnd(data, bits); err != nil {
+		return []uint64{}, err
+	}
+
+	return values, nil
+}
+
+// Decode a record, see DecodeStrict.
+func (s *Schema) DecodeStrict(data []byte) ([]uint32, error) {
+	if err := s.check(32, len(s.fields)); err != nil {
+		return []uint32{}, err
+	}
+
+	return DecodeStrict(s.fields, data)
+}
+
+// Decode a record of 64-bit values, see DecodeStrict64.
+func (s *Schema) DecodeStrict64(data []byte) ([]uint64, error) {
+	if err := s.check(64, len(s.fields)); err != nil {
+		return []uint64{}, err
+	}
+
+	return DecodeStrict64(s.fields, data)
+}
+
+func checkNotEmpty(fields []uint8, data []byte) error {
+	if len(data) == 0 && len(fields) > 0 {
+		return ErrEmpty
+	}
+
+	return nil
+}
+
+// Check that a record ending after the given number of bits is padded with
+// zero bits to the end of its last byte and that no bytes follow it.
+func checkRecordEnd(data []byte, bits int) error {
+	if bits%8 != 0 {
+		padding := data[bits/8] & (0xff >> uint(bits%8))
+		if padding != 0 {
+			return newError(ErrNonZeroPadding, -1, 0, bits, "non-zero padding bits, got 0x%02x in last byte", data[bits/8])
+		}
+	}
+
+	size := (bits + 7) / 8
+	if len(data) > size {
+		return newError(ErrTrailingData, -1, 0, size*8, "trailing data, got %d bytes after the end of the record", len(data)-size)
+	}
+
+	return nil
+}
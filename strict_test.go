@@ -0,0 +1,113 @@
+package govarint
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+type strictDecodeTestCase struct {
+	fields []uint8
+	data   []byte
+	err    error
+}
+
+var (
+	strictDecodeTests = []strictDecodeTestCase{
+		{[]uint8{1}, []byte{}, ErrEmpty},
+		{[]uint8{1}, nil, ErrEmpty},
+
+		// A 6-bit prefix claiming a 63-bit value.
+		{[]uint8{6}, []byte{0xfc, 0, 0, 0, 0, 0, 0, 0}, ErrInvalidWidth},
+		{[]uint8{6}, []byte{0x84, 0, 0, 0, 0}, ErrInvalidWidth},
+
+		// Cut short inside the width prefixes and inside a value.
+		{[]uint8{6, 6}, []byte{0x04}, ErrTruncated},
+		{[]uint8{4, 5}, []byte{0x17, 0x40}, ErrTruncated},
+
+		// The low bits of the last byte must be clear.
+		{[]uint8{1}, []byte{0x81}, ErrNonZeroPadding},
+		{[]uint8{4, 5}, []byte{0x17, 0x40, 0xe5}, ErrNonZeroPadding},
+
+		{[]uint8{1}, []byte{0x80, 0}, ErrTrailingData},
+		{[]uint8{4, 5}, []byte{0x17, 0x40, 0xe4, 0x00}, ErrTrailingData},
+	}
+)
+
+func TestDecodeStrict(t *testing.T) {
+	for _, tc := range decodeTests {
+		result, err := DecodeStrict(tc.fields, tc.data)
+		if err != nil {
+			t.Errorf("Unexpected decode error \"%s\" for %v", err, tc)
+			continue
+		}
+
+		for i, expected := range tc.values {
+			if expected != result[i] {
+				t.Errorf("Incorrect value, expected 0x%08x, got 0x%08x for %v", expected, result[i], tc)
+			}
+		}
+	}
+}
+
+func TestDecodeStrictErrors(t *testing.T) {
+	for _, tc := range strictDecodeTests {
+		_, err := DecodeStrict(tc.fields, tc.data)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("Expected error \"%s\", got: %v for %v", tc.err, err, tc)
+		}
+
+		_, err = DecodeStrict64(tc.fields, tc.data)
+		if tc.err == ErrInvalidWidth {
+			continue
+		}
+		if !errors.Is(err, tc.err) {
+			t.Errorf("Expected error \"%s\", got: %v for 64-bit %v", tc.err, err, tc)
+		}
+	}
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	if _, err := Decode([]uint8{3}, []byte{}); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+
+	if _, err := Decode64([]uint8{3}, []byte{}); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+
+	if values, err := Decode([]uint8{}, []byte{}); err != nil || len(values) != 0 {
+		t.Errorf("Expected no values and no error, got %v, %v", values, err)
+	}
+}
+
+func TestDecodeInvalidWidth(t *testing.T) {
+	_, err := Decode([]uint8{6}, []byte{0xfc, 0, 0, 0, 0, 0, 0, 0})
+	if !errors.Is(err, ErrInvalidWidth) {
+		t.Errorf("Expected ErrInvalidWidth, got %v", err)
+	}
+}
+
+// Random bytes must never make the strict decoder panic, and anything it
+// accepts must encode back to the same bytes.
+func TestDecodeStrictRandomInput(t *testing.T) {
+	fields := []uint8{3, Signed(3), 6, 3, 6}
+
+	for testCount := 0; testCount < 100000; testCount++ {
+		data := make([]byte, rand.Int31n(16))
+		rand.Read(data)
+
+		values, err := DecodeStrict(fields, data)
+		if err != nil {
+			continue
+		}
+
+		encoded, err := Encode(fields, values)
+		if err != nil {
+			t.Fatalf("Unexpected encode error \"%s\" for 0x%x", err, data)
+		}
+		if string(encoded) != string(data) {
+			t.Fatalf("Expected 0x%x, got 0x%x", data, encoded)
+		}
+	}
+}